@@ -0,0 +1,223 @@
+// Package bvh 提供一个针对静态 3D 点集合的轴对齐包围盒层级树 (AABB BVH)，
+// 用于在 O(log N) 内回答“半径范围查询”和“这条视线有没有被挡住”这两个问题，
+// 取代对树上所有格子做线性扫描。
+package bvh
+
+import "sort"
+
+// Point 是 BVH 索引的一个位置，调用方负责把自己的坐标系换算成这里的 X/Y/Z。
+type Point struct {
+	X, Y, Z float64
+}
+
+type aabb struct {
+	Min, Max Point
+}
+
+func pointBounds(p Point) aabb {
+	return aabb{Min: p, Max: p}
+}
+
+func union(a, b aabb) aabb {
+	return aabb{
+		Min: Point{min(a.Min.X, b.Min.X), min(a.Min.Y, b.Min.Y), min(a.Min.Z, b.Min.Z)},
+		Max: Point{max(a.Max.X, b.Max.X), max(a.Max.Y, b.Max.Y), max(a.Max.Z, b.Max.Z)},
+	}
+}
+
+func min(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// closestPointOnAABB 返回 box 上离 p 最近的点，用于球-AABB 相交测试。
+func closestPointOnAABB(p Point, box aabb) Point {
+	clamp := func(v, lo, hi float64) float64 {
+		if v < lo {
+			return lo
+		}
+		if v > hi {
+			return hi
+		}
+		return v
+	}
+	return Point{
+		X: clamp(p.X, box.Min.X, box.Max.X),
+		Y: clamp(p.Y, box.Min.Y, box.Max.Y),
+		Z: clamp(p.Z, box.Min.Z, box.Max.Z),
+	}
+}
+
+func distSq(a, b Point) float64 {
+	dx, dy, dz := a.X-b.X, a.Y-b.Y, a.Z-b.Z
+	return dx*dx + dy*dy + dz*dz
+}
+
+func sphereIntersectsAABB(center Point, radius float64, box aabb) bool {
+	closest := closestPointOnAABB(center, box)
+	return distSq(center, closest) <= radius*radius
+}
+
+// expand 返回把 box 向外扩张 margin 之后的包围盒，用于线段-AABB 粗筛。
+func (b aabb) expand(margin float64) aabb {
+	return aabb{
+		Min: Point{b.Min.X - margin, b.Min.Y - margin, b.Min.Z - margin},
+		Max: Point{b.Max.X + margin, b.Max.Y + margin, b.Max.Z + margin},
+	}
+}
+
+func segmentAABB(from, to Point) aabb {
+	return aabb{
+		Min: Point{min(from.X, to.X), min(from.Y, to.Y), min(from.Z, to.Z)},
+		Max: Point{max(from.X, to.X), max(from.Y, to.Y), max(from.Z, to.Z)},
+	}
+}
+
+func aabbOverlap(a, b aabb) bool {
+	return a.Min.X <= b.Max.X && a.Max.X >= b.Min.X &&
+		a.Min.Y <= b.Max.Y && a.Max.Y >= b.Min.Y &&
+		a.Min.Z <= b.Max.Z && a.Max.Z >= b.Min.Z
+}
+
+// distPointToSegmentSq 返回 p 到线段 ab 的最短距离的平方。
+func distPointToSegmentSq(p, a, b Point) float64 {
+	abx, aby, abz := b.X-a.X, b.Y-a.Y, b.Z-a.Z
+	apx, apy, apz := p.X-a.X, p.Y-a.Y, p.Z-a.Z
+	abLenSq := abx*abx + aby*aby + abz*abz
+	if abLenSq == 0 {
+		return distSq(p, a)
+	}
+	t := (apx*abx + apy*aby + apz*abz) / abLenSq
+	t = max(0, min(1, t))
+	closest := Point{a.X + t*abx, a.Y + t*aby, a.Z + t*abz}
+	return distSq(p, closest)
+}
+
+type node struct {
+	bounds      aabb
+	left, right *node
+	leaf        bool
+	index       int // 叶子节点对应的原始下标
+}
+
+// Tree 是建在一组静态点上的 BVH。调用方通过下标引用自己的数据（比如
+// main 包里的 treeData），Tree 本身只存坐标。
+type Tree struct {
+	points []Point
+	root   *node
+}
+
+// Build 一次性构建 BVH；按最长轴中位数切分，适合静态场景一次建树、多帧复用。
+func Build(points []Point) *Tree {
+	t := &Tree{points: points}
+	idx := make([]int, len(points))
+	for i := range idx {
+		idx[i] = i
+	}
+	t.root = build(points, idx)
+	return t
+}
+
+func build(points []Point, idx []int) *node {
+	if len(idx) == 0 {
+		return nil
+	}
+	bounds := pointBounds(points[idx[0]])
+	for _, i := range idx[1:] {
+		bounds = union(bounds, pointBounds(points[i]))
+	}
+	if len(idx) == 1 {
+		return &node{bounds: bounds, leaf: true, index: idx[0]}
+	}
+
+	spanX := bounds.Max.X - bounds.Min.X
+	spanY := bounds.Max.Y - bounds.Min.Y
+	spanZ := bounds.Max.Z - bounds.Min.Z
+	axis := 0
+	if spanY > spanX && spanY >= spanZ {
+		axis = 1
+	} else if spanZ > spanX && spanZ >= spanY {
+		axis = 2
+	}
+
+	sort.Slice(idx, func(a, b int) bool {
+		pa, pb := points[idx[a]], points[idx[b]]
+		switch axis {
+		case 1:
+			return pa.Y < pb.Y
+		case 2:
+			return pa.Z < pb.Z
+		default:
+			return pa.X < pb.X
+		}
+	})
+
+	mid := len(idx) / 2
+	return &node{
+		bounds: bounds,
+		left:   build(points, idx[:mid]),
+		right:  build(points, idx[mid:]),
+	}
+}
+
+// Query 返回所有与 center 的欧氏距离不超过 radius 的点下标。
+func (t *Tree) Query(center Point, radius float64) []int {
+	var out []int
+	var walk func(n *node)
+	walk = func(n *node) {
+		if n == nil || !sphereIntersectsAABB(center, radius, n.bounds) {
+			return
+		}
+		if n.leaf {
+			if distSq(center, t.points[n.index]) <= radius*radius {
+				out = append(out, n.index)
+			}
+			return
+		}
+		walk(n.left)
+		walk(n.right)
+	}
+	walk(t.root)
+	return out
+}
+
+// Occludes 报告是否存在另一个点（不是 skipIndex 本身）挡在 from 与
+// target 之间：既要落在 from->target 线段 margin 范围内，又要比 target
+// 更靠近摄像机（Z 更大，Z 轴正方向朝向观众，与本项目里 Z>=0 表示"在屏幕前方"
+// 的约定一致）。
+func (t *Tree) Occludes(from, target Point, skipIndex int, margin float64) bool {
+	segBounds := segmentAABB(from, target).expand(margin)
+	found := false
+	var walk func(n *node)
+	walk = func(n *node) {
+		if n == nil || found || !aabbOverlap(segBounds, n.bounds.expand(margin)) {
+			return
+		}
+		if n.leaf {
+			if n.index == skipIndex {
+				return
+			}
+			p := t.points[n.index]
+			if p.Z <= target.Z {
+				return
+			}
+			if distPointToSegmentSq(p, from, target) <= margin*margin {
+				found = true
+			}
+			return
+		}
+		walk(n.left)
+		walk(n.right)
+	}
+	walk(t.root)
+	return found
+}
@@ -0,0 +1,73 @@
+package bvh
+
+import "testing"
+
+func buildTestTree() (*Tree, []Point) {
+	points := []Point{
+		{X: 0, Y: 0, Z: 0},  // 0: 原点
+		{X: 1, Y: 0, Z: 0},  // 1: 原点附近
+		{X: 5, Y: 0, Z: 0},  // 2: 远处
+		{X: 0, Y: 0, Z: 5},  // 3: 挡在原点和观察点之间
+		{X: 0, Y: 0, Z: -5}, // 4: 在原点背后（更远离观察点）
+	}
+	return Build(points), points
+}
+
+func TestQuery(t *testing.T) {
+	tree, _ := buildTestTree()
+
+	tests := []struct {
+		name   string
+		center Point
+		radius float64
+		want   map[int]bool
+	}{
+		{"near origin", Point{0, 0, 0}, 1.5, map[int]bool{0: true, 1: true}},
+		{"whole tree", Point{0, 0, 0}, 100, map[int]bool{0: true, 1: true, 2: true, 3: true, 4: true}},
+		{"nothing in range", Point{50, 50, 50}, 1, map[int]bool{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tree.Query(tt.center, tt.radius)
+			gotSet := make(map[int]bool, len(got))
+			for _, idx := range got {
+				gotSet[idx] = true
+			}
+			if len(gotSet) != len(tt.want) {
+				t.Fatalf("Query(%v, %v) = %v, want %v", tt.center, tt.radius, gotSet, tt.want)
+			}
+			for idx := range tt.want {
+				if !gotSet[idx] {
+					t.Errorf("Query(%v, %v) missing index %d, got %v", tt.center, tt.radius, idx, gotSet)
+				}
+			}
+		})
+	}
+}
+
+func TestOccludes(t *testing.T) {
+	tree, _ := buildTestTree()
+
+	// 点 3 (Z=5) 挡在观察点 (Z=10) 和目标点 3 自身 (Z=5) 之间吗？不行，
+	// 因为 skipIndex 就是目标本身。换一个目标：原点 (index 0, Z=0)。
+	from := Point{X: 0, Y: 0, Z: 10}
+	target := Point{X: 0, Y: 0, Z: 0} // index 0
+
+	if !tree.Occludes(from, target, 0, 0.5) {
+		t.Errorf("Occludes(%v, %v): want true, point 3 (Z=5) sits between from and target", from, target)
+	}
+
+	// 把挡住视线的点排除在 BVH 之外：只查 index 1/2/4，没有东西挡在
+	// (0,0,10) 与 (0,0,0) 之间。
+	sparse := Build([]Point{{X: 1, Y: 0, Z: 0}, {X: 5, Y: 0, Z: 0}, {X: 0, Y: 0, Z: -5}})
+	if sparse.Occludes(from, target, -1, 0.5) {
+		t.Errorf("Occludes should be false when no point sits on the from->target segment")
+	}
+
+	// 偏移超出 margin 的点不应算作遮挡。
+	off := Build([]Point{{X: 3, Y: 0, Z: 5}})
+	if off.Occludes(from, target, -1, 0.5) {
+		t.Errorf("Occludes should be false when the candidate point is outside margin of the segment")
+	}
+}
@@ -0,0 +1,69 @@
+// Package camera 把观察者的视角（偏航、俯仰、缩放）和投影计算收拢到一处，
+// 取代原来写死的 `screenX = midX + starRelX*2.0` 式投影，让整棵树可以绕着
+// 一个真正的 3D 相机旋转、缩放，而不只是一层固定的 2D 剪影。
+package camera
+
+import "math"
+
+// Vec3 是树坐标系里的一个点：X/Z 是水平面，Y 是竖直方向（沿用 main 包里
+// 0 在树底、负值朝上的约定）。这里单独定义一份，避免 internal 包反过来
+// 依赖 main 包。
+type Vec3 struct {
+	X, Y, Z float64
+}
+
+// Camera 用偏航角(Yaw)、俯仰角(Pitch，弧度)和缩放(Zoom)描述观察者的视角，
+// Project 据此把树坐标系里的点转换成屏幕坐标和深度。
+type Camera struct {
+	Yaw, Pitch float64
+	Zoom       float64
+}
+
+const pitchLimit = 89 * math.Pi / 180 // 俯仰角上限，避免转到画面上下颠倒
+
+// New 返回一个朝向正前方、不缩放的默认相机。
+func New() *Camera {
+	return &Camera{Zoom: 1.0}
+}
+
+// Rotate 按 dYaw/dPitch（弧度）调整朝向，俯仰角会被夹在 ±89° 内。
+func (c *Camera) Rotate(dYaw, dPitch float64) {
+	c.Yaw += dYaw
+	c.Pitch += dPitch
+	if c.Pitch > pitchLimit {
+		c.Pitch = pitchLimit
+	}
+	if c.Pitch < -pitchLimit {
+		c.Pitch = -pitchLimit
+	}
+}
+
+// ZoomBy 按倍率调整缩放，夹在 [0.3, 3.0] 内防止画面缩没或大到铺满屏幕。
+func (c *Camera) ZoomBy(factor float64) {
+	c.Zoom *= factor
+	if c.Zoom < 0.3 {
+		c.Zoom = 0.3
+	}
+	if c.Zoom > 3.0 {
+		c.Zoom = 3.0
+	}
+}
+
+// Project 把一个树坐标系下的点先绕竖直轴转 Yaw、再绕水平轴转 Pitch，最后按
+// Zoom 缩放，换算成相对于 (originX, originY) 的屏幕坐标。depth 是旋转后的
+// Z：Z 越大说明旋转后越靠近观众，可以直接拿来做 back-to-front 深度排序，
+// 也可以用来判断某点是否转到了树的背面。
+func (c *Camera) Project(v Vec3, originX, originY int) (screenX, screenY int, depth float64) {
+	cosY, sinY := math.Cos(c.Yaw), math.Sin(c.Yaw)
+	x1 := v.X*cosY + v.Z*sinY
+	z1 := -v.X*sinY + v.Z*cosY
+
+	cosP, sinP := math.Cos(c.Pitch), math.Sin(c.Pitch)
+	y2 := v.Y*cosP - z1*sinP
+	z2 := v.Y*sinP + z1*cosP
+
+	screenX = originX + int(math.Round(x1*c.Zoom))
+	screenY = originY + int(math.Round(y2*c.Zoom))
+	depth = z2
+	return screenX, screenY, depth
+}
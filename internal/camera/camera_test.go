@@ -0,0 +1,111 @@
+package camera
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	c := New()
+	if c.Yaw != 0 || c.Pitch != 0 || c.Zoom != 1.0 {
+		t.Fatalf("New() = %+v, want Yaw=0 Pitch=0 Zoom=1.0", c)
+	}
+}
+
+func TestRotatePitchClamp(t *testing.T) {
+	tests := []struct {
+		name      string
+		dYaw      float64
+		dPitch    float64
+		wantPitch float64
+	}{
+		{"within limit", 0, 10 * math.Pi / 180, 10 * math.Pi / 180},
+		{"clamped at upper limit", 0, 200 * math.Pi / 180, pitchLimit},
+		{"clamped at lower limit", 0, -200 * math.Pi / 180, -pitchLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New()
+			c.Rotate(tt.dYaw, tt.dPitch)
+			if math.Abs(c.Pitch-tt.wantPitch) > 1e-9 {
+				t.Errorf("Pitch = %v, want %v", c.Pitch, tt.wantPitch)
+			}
+		})
+	}
+}
+
+func TestRotateYawAccumulates(t *testing.T) {
+	c := New()
+	c.Rotate(0.5, 0)
+	c.Rotate(0.25, 0)
+	if math.Abs(c.Yaw-0.75) > 1e-9 {
+		t.Errorf("Yaw = %v, want 0.75", c.Yaw)
+	}
+}
+
+func TestZoomByClamp(t *testing.T) {
+	tests := []struct {
+		name   string
+		factor float64
+		want   float64
+	}{
+		{"zoom in within range", 1.5, 1.5},
+		{"zoom out clamped to min", 0.01, 0.3},
+		{"zoom in clamped to max", 100, 3.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New()
+			c.ZoomBy(tt.factor)
+			if math.Abs(c.Zoom-tt.want) > 1e-9 {
+				t.Errorf("Zoom = %v, want %v", c.Zoom, tt.want)
+			}
+		})
+	}
+}
+
+func TestProjectIdentity(t *testing.T) {
+	c := New()
+	sx, sy, depth := c.Project(Vec3{X: 3, Y: 4, Z: 5}, 10, 20)
+	if sx != 13 || sy != 24 || depth != 5 {
+		t.Errorf("Project() = (%d, %d, %g), want (13, 24, 5)", sx, sy, depth)
+	}
+}
+
+func TestProjectYawRotation(t *testing.T) {
+	c := New()
+	c.Yaw = math.Pi / 2 // 90 度：X 轴应该转到原来 Z 轴的位置
+	sx, sy, depth := c.Project(Vec3{X: 1, Y: 0, Z: 0}, 0, 0)
+	if sx != 0 {
+		t.Errorf("Project() screenX = %d, want 0 after a 90 degree yaw", sx)
+	}
+	if sy != 0 {
+		t.Errorf("Project() screenY = %d, want 0", sy)
+	}
+	if math.Abs(depth-(-1)) > 1e-9 {
+		t.Errorf("Project() depth = %g, want -1 after a 90 degree yaw", depth)
+	}
+}
+
+func TestProjectZoomScalesScreenCoords(t *testing.T) {
+	c := New()
+	c.Zoom = 2.0
+	sx, sy, _ := c.Project(Vec3{X: 3, Y: 4, Z: 0}, 0, 0)
+	if sx != 6 || sy != 8 {
+		t.Errorf("Project() = (%d, %d), want (6, 8) at Zoom=2.0", sx, sy)
+	}
+}
+
+func TestProjectPitchTiltsDepth(t *testing.T) {
+	c := New()
+	c.Pitch = math.Pi / 2 // 90 度俯仰：Y 和 Z 互换
+	_, sy, depth := c.Project(Vec3{X: 0, Y: 0, Z: 5}, 0, 0)
+	if math.Abs(depth-0) > 1e-9 {
+		t.Errorf("Project() depth = %g, want ~0 after a 90 degree pitch on a pure-Z point", depth)
+	}
+	if sy != -5 {
+		t.Errorf("Project() screenY = %d, want -5 after a 90 degree pitch on a pure-Z point", sy)
+	}
+}
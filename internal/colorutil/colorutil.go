@@ -0,0 +1,33 @@
+// Package colorutil 收拢跨包共用的颜色换算辅助函数，避免 main、particles、
+// theme 里各自维护一份等价的 HSV→RGB 实现。
+package colorutil
+
+import (
+	"math"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// HSVToRGB 把 HSV（h 为 0~360 度，s/v 为 0~1）转换成 tcell 的 RGB 颜色，
+// 供星星变色、烟花子粒子配色、主题调色板生成等场景统一复用。
+func HSVToRGB(h, s, v float64) tcell.Color {
+	c := v * s
+	x := c * (1 - math.Abs(math.Mod(h/60.0, 2)-1))
+	m := v - c
+	var r, g, b float64
+	switch {
+	case 0 <= h && h < 60:
+		r, g, b = c, x, 0
+	case 60 <= h && h < 120:
+		r, g, b = x, c, 0
+	case 120 <= h && h < 180:
+		r, g, b = 0, c, x
+	case 180 <= h && h < 240:
+		r, g, b = 0, x, c
+	case 240 <= h && h < 300:
+		r, g, b = x, 0, c
+	case 300 <= h && h < 360:
+		r, g, b = c, 0, x
+	}
+	return tcell.NewRGBColor(int32((r+m)*255), int32((g+m)*255), int32((b+m)*255))
+}
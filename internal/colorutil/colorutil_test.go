@@ -0,0 +1,27 @@
+package colorutil
+
+import "testing"
+
+func TestHSVToRGB(t *testing.T) {
+	tests := []struct {
+		name    string
+		h, s, v float64
+		r, g, b int32
+	}{
+		{"red", 0, 1, 1, 255, 0, 0},
+		{"green", 120, 1, 1, 0, 255, 0},
+		{"blue", 240, 1, 1, 0, 0, 255},
+		{"white", 0, 0, 1, 255, 255, 255},
+		{"black", 0, 0, 0, 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := HSVToRGB(tt.h, tt.s, tt.v)
+			r, g, b := got.RGB()
+			if int32(r) != tt.r || int32(g) != tt.g || int32(b) != tt.b {
+				t.Errorf("HSVToRGB(%g, %g, %g) = %d,%d,%d; want %d,%d,%d", tt.h, tt.s, tt.v, r, g, b, tt.r, tt.g, tt.b)
+			}
+		})
+	}
+}
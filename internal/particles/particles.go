@@ -0,0 +1,256 @@
+// Package particles 把原先散落在 main.go 里的拖尾粒子逻辑，收敛成一个
+// 可组合的发射器 (Emitter) 体系：每个发射器各自负责生成、运动学积分和渲染
+// 自己的一批粒子，主循环只需要每帧对启用的发射器依次调用 Update/Render。
+package particles
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/AisuKyobu/christmas-cli-tree/internal/colorutil"
+	"github.com/gdamore/tcell/v2"
+)
+
+// Vec3 是发射器内部使用的简易 3D 坐标/速度类型，刻意不依赖 main 包，
+// 避免在 internal 包和 main 包之间形成循环依赖。
+type Vec3 struct {
+	X, Y, Z float64
+}
+
+// Particle 是一个存活中的粒子。
+type Particle struct {
+	Pos, Vel Vec3
+	Life     float64 // 剩余生命，降到 0 及以下时粒子被移除（烟花子粒子从 1.0 起，星星拖尾粒子从 StarTrailEmitter.Life 起）
+	LifeRate float64 // 生命衰减速度：FireworkEmitter 按每秒计，StarTrailEmitter 按每个参考帧计（见 starTrailReferenceFPS）
+	Char     rune
+	Color    tcell.Color
+}
+
+// Surface 是渲染目标需要满足的最小接口，tcell.Screen 和 main 包里的帧缓冲
+// 都实现了它，发射器因此不需要关心自己到底是直接画在屏幕上，还是先写进一层
+// 脏格子双缓冲。
+type Surface interface {
+	SetContent(x, y int, mainc rune, combc []rune, style tcell.Style)
+	Size() (int, int)
+}
+
+// Emitter 是所有粒子发射器的统一接口，主循环每帧调用一次 Update 再调用 Render。
+type Emitter interface {
+	// Update 按 dt（秒）推进发射器状态：生成新粒子、施加重力/阻力、剔除死亡粒子。
+	Update(dt float64)
+	// Render 把当前存活的粒子画到 surface 上；bg 是需要叠加的背景色，保持与
+	// 天空/树渲染一致的背景，避免重绘出现的背景闪烁。
+	Render(surface Surface, bg tcell.Color)
+}
+
+func renderDots(particles []Particle, surface Surface, bg tcell.Color) {
+	w, h := surface.Size()
+	for _, p := range particles {
+		if p.Pos.X < 0 || p.Pos.X >= float64(w) || p.Pos.Y < 0 || p.Pos.Y >= float64(h) {
+			continue
+		}
+		st := tcell.StyleDefault.Foreground(p.Color).Background(bg)
+		surface.SetContent(int(p.Pos.X), int(p.Pos.Y), p.Char, nil, st)
+	}
+}
+
+// --- 星星拖尾发射器 ---
+
+// StarTrailEmitter 在星星当前位置持续撒出少量慢慢下落、慢慢熄灭的粒子，
+// 对应原来的 spawnParticles/updateParticles。
+type StarTrailEmitter struct {
+	Source func() (pos Vec3, color tcell.Color) // 每帧调用一次，获取星星当前位置与颜色
+	Life   float64                              // 粒子初始生命
+	Decay  float64                              // 每个参考帧（1/starTrailReferenceFPS 秒）的生命衰减量
+	Extra  int                                  // Z>=0（星星在前）时额外生成的粒子数
+
+	rnd        *rand.Rand
+	particles  []Particle
+	spawnAccum float64 // 按 dt 折算后尚未凑够一整颗的生成量，见 Update
+}
+
+// NewStarTrailEmitter 创建一个星星拖尾发射器。
+func NewStarTrailEmitter(rnd *rand.Rand, life, decay float64, extra int, source func() (Vec3, tcell.Color)) *StarTrailEmitter {
+	return &StarTrailEmitter{Source: source, Life: life, Decay: decay, Extra: extra, rnd: rnd}
+}
+
+// starTrailReferenceFPS 是 Life/Decay/速度这些魔数最初标定时假设的帧率。
+// 生成速率、位移和生命衰减都按 dt/referenceDt 折算成每秒量纲再乘回 dt，
+// 这样默认 FPS 下画面和原来一致，而调低/调高 -scene 的 fps 时轨迹密度和
+// 衰减速度也会正确地随之缩放，不再像过去那样隐含地按"每 tick"计量。
+const starTrailReferenceFPS = 25.0
+
+func (e *StarTrailEmitter) Update(dt float64) {
+	pos, color := e.Source()
+	ticks := dt * starTrailReferenceFPS
+
+	count := e.rnd.Intn(3) + 2
+	if pos.Z >= 0 {
+		count += e.Extra
+	}
+	e.spawnAccum += float64(count) * ticks
+	for e.spawnAccum >= 1 {
+		e.spawnAccum--
+		offsetX := (e.rnd.Float64() - 0.5) * 2.0
+		offsetY := (e.rnd.Float64() - 0.5) * 1.0
+		e.particles = append(e.particles, Particle{
+			Pos:      Vec3{X: pos.X + offsetX, Y: pos.Y + offsetY, Z: pos.Z},
+			Vel:      Vec3{X: (e.rnd.Float64() - 0.5) * 0.2, Y: e.rnd.Float64() * 0.2},
+			Life:     e.Life,
+			LifeRate: e.Decay,
+			Char:     '.',
+			Color:    color,
+		})
+	}
+
+	var alive []Particle
+	for _, p := range e.particles {
+		p.Pos.X += p.Vel.X * ticks
+		p.Pos.Y += p.Vel.Y * ticks
+		p.Life -= p.LifeRate * ticks
+		if p.Life > 0 {
+			alive = append(alive, p)
+		}
+	}
+	e.particles = alive
+}
+
+func (e *StarTrailEmitter) Render(surface Surface, bg tcell.Color) {
+	renderDots(e.particles, surface, bg)
+}
+
+// --- 烟花发射器 ---
+
+// rocket 是烟花升空阶段的弹体，到达顶点后炸开成一圈 spark。
+type rocket struct {
+	pos, vel Vec3
+}
+
+// FireworkEmitter 周期性地从树顶上方发射烟花：弹体带初速度 vy<0 向上飞行，
+// 每帧叠加重力 Gravity，到达顶点（vy 由负转正）后向四个象限各自撒出一批
+// 带随机寿命、随机色相的子粒子。
+type FireworkEmitter struct {
+	// Bounds 返回烟花可以发射的区域：width 为屏幕宽度，topY 为树顶所在行
+	// （烟花应在其上方炸开），groundY 为发射起点所在行。
+	Bounds func() (width, topY, groundY int)
+
+	Gravity       float64 // 每秒重力加速度（正值，向下）
+	Drag          float64 // 每秒速度衰减比例的空气阻力系数，0 表示无阻力
+	ChildrenPerQ  int     // 每个象限炸开的子粒子数
+	SpawnInterval float64 // 平均发射间隔（秒）
+
+	rnd           *rand.Rand
+	rockets       []rocket
+	sparks        []Particle
+	spawnCooldown float64
+}
+
+// NewFireworkEmitter 创建一个烟花发射器。
+func NewFireworkEmitter(rnd *rand.Rand, gravity, drag float64, childrenPerQuadrant int, spawnInterval float64, bounds func() (int, int, int)) *FireworkEmitter {
+	return &FireworkEmitter{
+		Bounds:        bounds,
+		Gravity:       gravity,
+		Drag:          drag,
+		ChildrenPerQ:  childrenPerQuadrant,
+		SpawnInterval: spawnInterval,
+		rnd:           rnd,
+		spawnCooldown: spawnInterval * rnd.Float64(),
+	}
+}
+
+func (e *FireworkEmitter) Update(dt float64) {
+	e.spawnCooldown -= dt
+	if e.spawnCooldown <= 0 {
+		e.launch()
+		e.spawnCooldown = e.SpawnInterval*0.5 + e.rnd.Float64()*e.SpawnInterval
+	}
+
+	drag := math.Max(0, 1-e.Drag*dt)
+
+	var liveRockets []rocket
+	for _, r := range e.rockets {
+		r.vel.Y += e.Gravity * dt
+		r.vel.X *= drag
+		r.pos.X += r.vel.X * dt
+		r.pos.Y += r.vel.Y * dt
+		if r.vel.Y >= 0 {
+			// 速度由负转正，说明已经越过顶点，在此处炸开。
+			e.explode(r.pos)
+			continue
+		}
+		liveRockets = append(liveRockets, r)
+	}
+	e.rockets = liveRockets
+
+	var aliveSparks []Particle
+	for _, p := range e.sparks {
+		p.Vel.Y += e.Gravity * dt * 0.4 // 子粒子受到的重力比弹体弱一些，炸开效果更舒展
+		p.Vel.X *= drag
+		p.Vel.Y *= drag
+		p.Pos.X += p.Vel.X * dt
+		p.Pos.Y += p.Vel.Y * dt
+		p.Life -= p.LifeRate * dt
+		if p.Life > 0 {
+			aliveSparks = append(aliveSparks, p)
+		}
+	}
+	e.sparks = aliveSparks
+}
+
+func (e *FireworkEmitter) launch() {
+	width, topY, groundY := e.Bounds()
+	if width <= 0 || groundY <= topY {
+		return
+	}
+	x := float64(e.rnd.Intn(width))
+	// 初速度足以让弹体越过树顶 topY，在 groundY 到 topY 高度的 110%~140% 处炸开。
+	targetHeight := float64(groundY-topY) * (1.1 + e.rnd.Float64()*0.3)
+	vy := -math.Sqrt(2 * e.Gravity * targetHeight)
+	e.rockets = append(e.rockets, rocket{
+		pos: Vec3{X: x, Y: float64(groundY)},
+		vel: Vec3{X: (e.rnd.Float64() - 0.5) * 0.5, Y: vy},
+	})
+}
+
+// quadrantSigns 给出 4 个象限各自的 (vx, vy) 符号，保证爆炸粒子向四个方向均匀散开。
+var quadrantSigns = [4][2]float64{
+	{1, -1},  // 右上
+	{-1, -1}, // 左上
+	{-1, 1},  // 左下
+	{1, 1},   // 右下
+}
+
+func (e *FireworkEmitter) explode(at Vec3) {
+	hueBase := e.rnd.Float64() * 360
+	for _, signs := range quadrantSigns {
+		for i := 0; i < e.ChildrenPerQ; i++ {
+			speed := 2.0 + e.rnd.Float64()*6.0
+			angle := e.rnd.Float64() * math.Pi / 2
+			vx := math.Cos(angle) * speed * signs[0]
+			vy := math.Sin(angle) * speed * signs[1]
+			lasted := 0.6 + e.rnd.Float64()*0.8 // 每颗子粒子的随机寿命（秒）
+			hue := math.Mod(hueBase+e.rnd.Float64()*50-25+360, 360)
+
+			e.sparks = append(e.sparks, Particle{
+				Pos:      at,
+				Vel:      Vec3{X: vx, Y: vy},
+				Life:     1.0,
+				LifeRate: 1.0 / lasted,
+				Char:     '*',
+				Color:    colorutil.HSVToRGB(hue, 1.0, 1.0),
+			})
+		}
+	}
+}
+
+func (e *FireworkEmitter) Render(surface Surface, bg tcell.Color) {
+	for _, r := range e.rockets {
+		w, h := surface.Size()
+		if r.pos.X < 0 || r.pos.X >= float64(w) || r.pos.Y < 0 || r.pos.Y >= float64(h) {
+			continue
+		}
+		st := tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(bg)
+		surface.SetContent(int(r.pos.X), int(r.pos.Y), '|', nil, st)
+	}
+	renderDots(e.sparks, surface, bg)
+}
@@ -0,0 +1,150 @@
+package particles
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func fixedStarSource(pos Vec3) func() (Vec3, tcell.Color) {
+	return func() (Vec3, tcell.Color) { return pos, tcell.ColorWhite }
+}
+
+func TestStarTrailEmitterAccumulatesFractionalSpawns(t *testing.T) {
+	e := NewStarTrailEmitter(rand.New(rand.NewSource(7)), 1.0, 0.0, 0, fixedStarSource(Vec3{X: 0, Y: 0, Z: -1}))
+
+	// 远小于生成一颗粒子所需的 dt：多次调用也不应该凑出一颗粒子。
+	tinyDt := 0.001 / starTrailReferenceFPS
+	for i := 0; i < 5; i++ {
+		e.Update(tinyDt)
+	}
+	if len(e.particles) != 0 {
+		t.Fatalf("tiny dt steps should not yet accumulate a full particle, got %d", len(e.particles))
+	}
+
+	for i := 0; i < 2000; i++ {
+		e.Update(tinyDt)
+	}
+	if len(e.particles) == 0 {
+		t.Fatalf("accumulated dt should eventually spawn particles")
+	}
+}
+
+func TestStarTrailEmitterSpawnsExtraWhenStarInFront(t *testing.T) {
+	behind := NewStarTrailEmitter(rand.New(rand.NewSource(1)), 1.0, 0.0, 5, fixedStarSource(Vec3{X: 0, Y: 0, Z: -1}))
+	front := NewStarTrailEmitter(rand.New(rand.NewSource(1)), 1.0, 0.0, 5, fixedStarSource(Vec3{X: 0, Y: 0, Z: 1}))
+
+	behind.Update(1.0 / starTrailReferenceFPS)
+	front.Update(1.0 / starTrailReferenceFPS)
+
+	if len(front.particles) <= len(behind.particles) {
+		t.Errorf("star in front (Z>=0) should spawn more trail particles than star behind the tree: front=%d behind=%d",
+			len(front.particles), len(behind.particles))
+	}
+}
+
+func findByLifeRate(particles []Particle, rate float64) (Particle, bool) {
+	for _, p := range particles {
+		if p.LifeRate == rate {
+			return p, true
+		}
+	}
+	return Particle{}, false
+}
+
+func TestStarTrailEmitterParticlesDieAfterLifeExpires(t *testing.T) {
+	// Decay=0.1 只用来给持续生成的新粒子标定生命周期；我们手动插入一颗
+	// LifeRate=0.5 的粒子做标记，这样才能在每帧都有新粒子生成的背景噪音下
+	// 单独跟踪它的生死，不被同一帧新生成的粒子混淆。
+	e := NewStarTrailEmitter(rand.New(rand.NewSource(3)), 1.0, 0.1, 0, fixedStarSource(Vec3{X: 0, Y: 0, Z: -1}))
+	e.particles = []Particle{{Life: 1.0, LifeRate: 0.5}}
+
+	e.Update(1.0 / starTrailReferenceFPS)
+	if p, ok := findByLifeRate(e.particles, 0.5); !ok || p.Life <= 0 {
+		t.Fatalf("expected marked particle alive with positive life after one tick, got %+v (found=%v)", p, ok)
+	}
+
+	e.Update(1.0 / starTrailReferenceFPS)
+	if _, ok := findByLifeRate(e.particles, 0.5); ok {
+		t.Errorf("expected marked particle (Life=1.0, LifeRate=0.5) to be dead after two reference ticks")
+	}
+}
+
+func TestFireworkEmitterLaunchesImmediatelyWhenCooldownElapsed(t *testing.T) {
+	e := NewFireworkEmitter(rand.New(rand.NewSource(1)), 9.0, 0, 2, 3.0,
+		func() (int, int, int) { return 40, 0, 20 })
+	e.spawnCooldown = 0 // 强制立刻发射，不依赖 SpawnInterval*rnd.Float64() 的随机延迟
+	e.Update(0.01)
+
+	if len(e.rockets) != 1 {
+		t.Fatalf("expected exactly one rocket after spawnCooldown elapses, got %d", len(e.rockets))
+	}
+	if e.rockets[0].vel.Y >= 0 {
+		t.Errorf("launched rocket should have upward (negative) initial velocity, got %g", e.rockets[0].vel.Y)
+	}
+}
+
+func TestFireworkEmitterGravityIntegration(t *testing.T) {
+	e := NewFireworkEmitter(rand.New(rand.NewSource(1)), 10.0, 0, 1, 1000,
+		func() (int, int, int) { return 40, 0, 20 })
+	e.spawnCooldown = 1000 // 超长间隔，测试期间不会再随机发射新的
+	e.rockets = []rocket{{pos: Vec3{X: 5, Y: 10}, vel: Vec3{X: 0, Y: -5}}}
+
+	e.Update(0.1)
+
+	want := -5 + 10*0.1
+	if len(e.rockets) != 1 {
+		t.Fatalf("rocket should survive one tick while still moving upward, got %d rockets", len(e.rockets))
+	}
+	if math.Abs(e.rockets[0].vel.Y-want) > 1e-9 {
+		t.Errorf("vel.Y = %g, want %g after one gravity tick", e.rockets[0].vel.Y, want)
+	}
+}
+
+func TestFireworkEmitterExplodesAtApex(t *testing.T) {
+	e := NewFireworkEmitter(rand.New(rand.NewSource(1)), 10.0, 0, 3, 1000,
+		func() (int, int, int) { return 40, 0, 20 })
+	e.spawnCooldown = 1000
+	e.rockets = []rocket{{pos: Vec3{X: 5, Y: 10}, vel: Vec3{X: 0, Y: 1}}} // 已经过了顶点 (vel.Y>=0)
+
+	e.Update(0.1)
+
+	if len(e.rockets) != 0 {
+		t.Errorf("rocket past apex should be consumed by explode, got %d remaining", len(e.rockets))
+	}
+	want := 3 * len(quadrantSigns)
+	if len(e.sparks) != want {
+		t.Errorf("expected %d sparks after explosion (ChildrenPerQ * 4 quadrants), got %d", want, len(e.sparks))
+	}
+}
+
+func TestFireworkEmitterDragDampensVelocity(t *testing.T) {
+	e := NewFireworkEmitter(rand.New(rand.NewSource(1)), 0, 2.0, 1, 1000,
+		func() (int, int, int) { return 40, 0, 20 })
+	e.spawnCooldown = 1000
+	e.sparks = []Particle{{Pos: Vec3{X: 5, Y: 5}, Vel: Vec3{X: 10, Y: 0}, Life: 1.0, LifeRate: 0.1}}
+
+	e.Update(0.1)
+
+	if len(e.sparks) != 1 {
+		t.Fatalf("spark should still be alive, got %d", len(e.sparks))
+	}
+	if e.sparks[0].Vel.X >= 10 {
+		t.Errorf("Vel.X = %g, want it reduced by drag from 10", e.sparks[0].Vel.X)
+	}
+}
+
+func TestFireworkEmitterNoDragLeavesVelocityUnchanged(t *testing.T) {
+	e := NewFireworkEmitter(rand.New(rand.NewSource(1)), 0, 0, 1, 1000,
+		func() (int, int, int) { return 40, 0, 20 })
+	e.spawnCooldown = 1000
+	e.sparks = []Particle{{Pos: Vec3{X: 5, Y: 5}, Vel: Vec3{X: 10, Y: 0}, Life: 1.0, LifeRate: 0.1}}
+
+	e.Update(0.1)
+
+	if e.sparks[0].Vel.X != 10 {
+		t.Errorf("Vel.X = %g, want unchanged at 10 when Drag=0", e.sparks[0].Vel.X)
+	}
+}
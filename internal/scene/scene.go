@@ -0,0 +1,180 @@
+// Package scene 定义可外部配置的场景参数（树形、礼物、天空、动画速度等），
+// 支持从 YAML/JSON 文件加载，让使用者无需重新编译即可自定义圣诞树。
+package scene
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GiftConfig 对应主程序里手工列出的每一份礼物盒配置。
+type GiftConfig struct {
+	X     int    `yaml:"x" json:"x"`
+	W     int    `yaml:"w" json:"w"`
+	H     int    `yaml:"h" json:"h"`
+	Color string `yaml:"color" json:"color"` // "#RRGGBB"
+}
+
+// Config 是整棵树的可调参数集合，字段与原先散落在 main.go 里的常量一一对应。
+type Config struct {
+	TreeHeight    int `yaml:"tree_height" json:"tree_height"`
+	TreeBaseWidth int `yaml:"tree_base_width" json:"tree_base_width"`
+
+	StarSpeed    float64 `yaml:"star_speed" json:"star_speed"`
+	StarTimeStep float64 `yaml:"star_time_step" json:"star_time_step"`
+	LightRadius  float64 `yaml:"light_radius" json:"light_radius"`
+
+	ParticleInitialLife float64 `yaml:"particle_initial_life" json:"particle_initial_life"`
+
+	// DecorColors 是装饰品随机取色的调色板，十六进制 "#RRGGBB"。
+	DecorColors []string `yaml:"decor_colors" json:"decor_colors"`
+
+	// Gifts 是树底下摆放的礼物盒列表。
+	Gifts []GiftConfig `yaml:"gifts" json:"gifts"`
+
+	// SkyColor 是天空基底背景色，十六进制 "#RRGGBB"。
+	SkyColor string `yaml:"sky_color" json:"sky_color"`
+
+	// SnowDensity 是每帧新生成的雪花数量。
+	SnowDensity int `yaml:"snow_density" json:"snow_density"`
+
+	// FPS 控制主循环刷新率。
+	FPS int `yaml:"fps" json:"fps"`
+}
+
+// Default 返回与原始硬编码常量完全一致的内置场景，用作回退值和
+// --dump-default-scene 的输出内容。
+func Default() *Config {
+	return &Config{
+		TreeHeight:          22,
+		TreeBaseWidth:       30,
+		StarSpeed:           0.2,
+		StarTimeStep:        0.06,
+		LightRadius:         8.0,
+		ParticleInitialLife: 1.2,
+		DecorColors: []string{
+			"#FF0000", // Red
+			"#FFFF00", // Yellow
+			"#FF69B4", // HotPink
+			"#00FFFF", // Cyan
+		},
+		Gifts: []GiftConfig{
+			{X: -8, W: 3, H: 2, Color: "#FF0000"},
+			{X: 6, W: 4, H: 2, Color: "#0000FF"},
+		},
+		SkyColor:    "#060A28",
+		SnowDensity: 2,
+		FPS:         25,
+	}
+}
+
+// Load 根据文件扩展名解析 YAML 或 JSON 场景文件，并在返回前校验取值范围。
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("scene: read %s: %w", path, err)
+	}
+
+	cfg := Default()
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("scene: parse yaml %s: %w", path, err)
+		}
+	case ".json":
+		if err := json.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("scene: parse json %s: %w", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("scene: unsupported scene file extension %q (want .yaml, .yml or .json)", ext)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("scene: invalid scene %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Validate 检查各项取值是否落在合理范围内，便于在启动时给出清晰的报错而不是
+// 运行时崩溃或画面异常。
+func (c *Config) Validate() error {
+	switch {
+	case c.TreeHeight <= 0:
+		return fmt.Errorf("tree_height must be > 0, got %d", c.TreeHeight)
+	case c.TreeBaseWidth <= 0:
+		return fmt.Errorf("tree_base_width must be > 0, got %d", c.TreeBaseWidth)
+	case c.StarTimeStep <= 0:
+		return fmt.Errorf("star_time_step must be > 0, got %g", c.StarTimeStep)
+	case c.LightRadius <= 0:
+		return fmt.Errorf("light_radius must be > 0, got %g", c.LightRadius)
+	case c.ParticleInitialLife <= 0:
+		return fmt.Errorf("particle_initial_life must be > 0, got %g", c.ParticleInitialLife)
+	case len(c.DecorColors) == 0:
+		return fmt.Errorf("decor_colors must not be empty")
+	case c.FPS <= 0 || c.FPS > 120:
+		return fmt.Errorf("fps must be in (0, 120], got %d", c.FPS)
+	case c.SnowDensity < 0:
+		return fmt.Errorf("snow_density must be >= 0, got %d", c.SnowDensity)
+	}
+	if _, _, _, err := ParseHex(c.SkyColor); err != nil {
+		return fmt.Errorf("sky_color: %w", err)
+	}
+	for i, col := range c.DecorColors {
+		if _, _, _, err := ParseHex(col); err != nil {
+			return fmt.Errorf("decor_colors[%d]: %w", i, err)
+		}
+	}
+	for i, g := range c.Gifts {
+		if g.W <= 0 || g.H <= 0 {
+			return fmt.Errorf("gifts[%d]: w and h must be > 0, got w=%d h=%d", i, g.W, g.H)
+		}
+		if _, _, _, err := ParseHex(g.Color); err != nil {
+			return fmt.Errorf("gifts[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// ParseHex 把 "#RRGGBB" 形式的颜色解析成三个分量，供渲染端直接喂给
+// tcell.NewRGBColor，避免在每个调用处重复写十六进制解析代码。
+func ParseHex(hex string) (r, g, b int32, err error) {
+	h := strings.TrimPrefix(hex, "#")
+	if len(h) != 6 {
+		return 0, 0, 0, fmt.Errorf("invalid color %q, want \"#RRGGBB\"", hex)
+	}
+	v, err := strconv.ParseUint(h, 16, 32)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid color %q: %w", hex, err)
+	}
+	return int32(v>>16) & 0xFF, int32(v>>8) & 0xFF, int32(v) & 0xFF, nil
+}
+
+// Save 将场景写出为 YAML 或 JSON 文件，扩展名决定编码格式。
+func (c *Config) Save(path string) error {
+	var data []byte
+	var err error
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml", "":
+		data, err = yaml.Marshal(c)
+	case ".json":
+		data, err = json.MarshalIndent(c, "", "  ")
+	default:
+		return fmt.Errorf("scene: unsupported scene file extension %q (want .yaml, .yml or .json)", ext)
+	}
+	if err != nil {
+		return fmt.Errorf("scene: encode %s: %w", path, err)
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// DumpDefault 把内置的默认场景写成一份可编辑的起始文件，供 --dump-default-scene 使用。
+func DumpDefault(path string) error {
+	return Default().Save(path)
+}
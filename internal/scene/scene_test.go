@@ -0,0 +1,80 @@
+package scene
+
+import "testing"
+
+func TestParseHex(t *testing.T) {
+	tests := []struct {
+		name    string
+		hex     string
+		wantErr bool
+		r, g, b int32
+	}{
+		{"valid lowercase", "#ff0000", false, 255, 0, 0},
+		{"valid uppercase", "#00FF00", false, 0, 255, 0},
+		{"valid without hash", "0000ff", false, 0, 0, 255},
+		{"too short", "#fff", true, 0, 0, 0},
+		{"too long", "#ff00ff00", true, 0, 0, 0},
+		{"not hex", "#gggggg", true, 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, g, b, err := ParseHex(tt.hex)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseHex(%q) = %d,%d,%d, nil; want error", tt.hex, r, g, b)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseHex(%q) unexpected error: %v", tt.hex, err)
+			}
+			if r != tt.r || g != tt.g || b != tt.b {
+				t.Errorf("ParseHex(%q) = %d,%d,%d; want %d,%d,%d", tt.hex, r, g, b, tt.r, tt.g, tt.b)
+			}
+		})
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	valid := func() *Config {
+		c := Default()
+		return c
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(*Config)
+		wantErr bool
+	}{
+		{"default config is valid", func(c *Config) {}, false},
+		{"zero tree height", func(c *Config) { c.TreeHeight = 0 }, true},
+		{"zero tree base width", func(c *Config) { c.TreeBaseWidth = 0 }, true},
+		{"zero star time step", func(c *Config) { c.StarTimeStep = 0 }, true},
+		{"zero light radius", func(c *Config) { c.LightRadius = 0 }, true},
+		{"zero particle life", func(c *Config) { c.ParticleInitialLife = 0 }, true},
+		{"empty decor colors", func(c *Config) { c.DecorColors = nil }, true},
+		{"fps zero", func(c *Config) { c.FPS = 0 }, true},
+		{"fps too high", func(c *Config) { c.FPS = 121 }, true},
+		{"fps at upper bound", func(c *Config) { c.FPS = 120 }, false},
+		{"negative snow density", func(c *Config) { c.SnowDensity = -1 }, true},
+		{"bad sky color", func(c *Config) { c.SkyColor = "#zzzzzz" }, true},
+		{"bad decor color", func(c *Config) { c.DecorColors = []string{"#zzzzzz"} }, true},
+		{"gift with zero width", func(c *Config) { c.Gifts = []GiftConfig{{W: 0, H: 1, Color: "#FF0000"}} }, true},
+		{"gift with bad color", func(c *Config) { c.Gifts = []GiftConfig{{W: 1, H: 1, Color: "nope"}} }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := valid()
+			tt.mutate(c)
+			err := c.Validate()
+			if tt.wantErr && err == nil {
+				t.Errorf("Validate() = nil; want error")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Validate() = %v; want nil", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+// Package theme 把原来散落在 main.go 里各处的硬编码颜色（天空渐变、树叶、
+// 树干、装饰品调色板、星星变色速度）收拢成一组可在运行时切换的配色方案，
+// 供 `[`/`]` 循环切换、`c` 切换色盲友好调色板使用。
+package theme
+
+import (
+	"math"
+
+	"github.com/AisuKyobu/christmas-cli-tree/internal/colorutil"
+	"github.com/gdamore/tcell/v2"
+)
+
+// Theme 汇总一套配色方案里所有"产生颜色"的旋钮。
+type Theme struct {
+	Name string
+
+	// SkyTop/SkyBottom 是天空从屏幕顶部到底部的两段渐变色，取代原来单一的
+	// SkyBaseR/G/B 平涂背景。
+	SkyTop, SkyBottom tcell.Color
+
+	NeedleBase, NeedleLit tcell.Color
+	TrunkBase, TrunkLit   tcell.Color
+
+	// DecorPalette 是装饰品随机取色的调色板。
+	DecorPalette []tcell.Color
+	// SafePalette 是 colorblind-safe 模式下替代 DecorPalette 使用的调色板。
+	SafePalette []tcell.Color
+
+	// StarHueSpeed 控制星星彩虹变色的速度（动画时间 t 每增加 1 时色相变化的度数，
+	// t 每帧增加场景配置的 star_time_step；0 表示固定白色）。
+	StarHueSpeed float64
+}
+
+// Palette 返回当前应该使用的装饰品调色板：colorblindSafe 为 true 时用 SafePalette。
+func (t Theme) Palette(colorblindSafe bool) []tcell.Color {
+	if colorblindSafe {
+		return t.SafePalette
+	}
+	return t.DecorPalette
+}
+
+// SkyColorAt 按 frac（0=屏幕顶部，1=屏幕底部）在 SkyTop/SkyBottom 间线性插值，
+// 得到该行应该使用的天空背景色。
+func (t Theme) SkyColorAt(frac float64) tcell.Color {
+	if frac <= 0 {
+		return t.SkyTop
+	}
+	if frac >= 1 {
+		return t.SkyBottom
+	}
+	tr, tg, tb := t.SkyTop.RGB()
+	br, bg, bb := t.SkyBottom.RGB()
+	r := int32(float64(tr) + (float64(br)-float64(tr))*frac)
+	g := int32(float64(tg) + (float64(bg)-float64(tg))*frac)
+	b := int32(float64(tb) + (float64(bb)-float64(tb))*frac)
+	return tcell.NewRGBColor(r, g, b)
+}
+
+// proceduralPalette 以 baseHue 为起点，在色轮上等间隔取 n 个高饱和度颜色，
+// 用于从一个"基准色调"直接生成一整套装饰品调色板（比如 neon 主题）。
+func proceduralPalette(baseHue float64, n int) []tcell.Color {
+	colors := make([]tcell.Color, n)
+	for i := 0; i < n; i++ {
+		h := math.Mod(baseHue+float64(i)*(360.0/float64(n)), 360)
+		colors[i] = colorutil.HSVToRGB(h, 1.0, 1.0)
+	}
+	return colors
+}
+
+// ColorblindSafePalette 是跨主题共用的一套色盲友好装饰色（Okabe-Ito 配色）。
+var ColorblindSafePalette = []tcell.Color{
+	tcell.NewRGBColor(230, 159, 0),  // 橙
+	tcell.NewRGBColor(86, 180, 233), // 天蓝
+	tcell.NewRGBColor(240, 228, 66), // 黄
+	tcell.NewRGBColor(0, 114, 178),  // 蓝
+	tcell.NewRGBColor(213, 94, 0),   // 朱红
+}
+
+// Themes 是内置主题列表，按 `[`/`]` 循环的顺序排列。
+var Themes = []Theme{
+	{
+		Name:       "classic",
+		SkyTop:     tcell.NewRGBColor(6, 10, 40),
+		SkyBottom:  tcell.NewRGBColor(20, 25, 70),
+		NeedleBase: tcell.ColorGreen,
+		NeedleLit:  tcell.NewRGBColor(100, 255, 100),
+		TrunkBase:  tcell.NewRGBColor(101, 67, 33),
+		TrunkLit:   tcell.NewRGBColor(200, 150, 50),
+		DecorPalette: []tcell.Color{
+			tcell.NewRGBColor(255, 0, 0),
+			tcell.NewRGBColor(255, 255, 0),
+			tcell.NewRGBColor(255, 105, 180),
+			tcell.NewRGBColor(0, 255, 255),
+		},
+		SafePalette:  ColorblindSafePalette,
+		StarHueSpeed: 20,
+	},
+	{
+		Name:       "midnight",
+		SkyTop:     tcell.NewRGBColor(2, 2, 20),
+		SkyBottom:  tcell.NewRGBColor(10, 10, 45),
+		NeedleBase: tcell.NewRGBColor(10, 60, 40),
+		NeedleLit:  tcell.NewRGBColor(60, 160, 120),
+		TrunkBase:  tcell.NewRGBColor(60, 40, 25),
+		TrunkLit:   tcell.NewRGBColor(120, 90, 55),
+		DecorPalette: []tcell.Color{
+			tcell.NewRGBColor(180, 180, 255),
+			tcell.NewRGBColor(120, 120, 255),
+			tcell.NewRGBColor(200, 200, 255),
+		},
+		SafePalette:  ColorblindSafePalette,
+		StarHueSpeed: 10,
+	},
+	{
+		Name:       "pastel",
+		SkyTop:     tcell.NewRGBColor(40, 30, 50),
+		SkyBottom:  tcell.NewRGBColor(90, 70, 100),
+		NeedleBase: tcell.NewRGBColor(140, 200, 160),
+		NeedleLit:  tcell.NewRGBColor(200, 240, 210),
+		TrunkBase:  tcell.NewRGBColor(150, 120, 100),
+		TrunkLit:   tcell.NewRGBColor(190, 160, 140),
+		DecorPalette: []tcell.Color{
+			tcell.NewRGBColor(255, 200, 210),
+			tcell.NewRGBColor(255, 230, 180),
+			tcell.NewRGBColor(200, 220, 255),
+			tcell.NewRGBColor(220, 255, 220),
+		},
+		SafePalette:  ColorblindSafePalette,
+		StarHueSpeed: 15,
+	},
+	{
+		Name:         "neon",
+		SkyTop:       tcell.NewRGBColor(5, 0, 15),
+		SkyBottom:    tcell.NewRGBColor(25, 0, 40),
+		NeedleBase:   colorutil.HSVToRGB(150, 0.9, 0.6),
+		NeedleLit:    colorutil.HSVToRGB(150, 0.6, 1.0),
+		TrunkBase:    tcell.NewRGBColor(40, 20, 50),
+		TrunkLit:     tcell.NewRGBColor(90, 40, 110),
+		DecorPalette: proceduralPalette(300, 6),
+		SafePalette:  ColorblindSafePalette,
+		StarHueSpeed: 60,
+	},
+	{
+		Name:       "monochrome",
+		SkyTop:     tcell.NewRGBColor(10, 10, 10),
+		SkyBottom:  tcell.NewRGBColor(40, 40, 40),
+		NeedleBase: tcell.NewRGBColor(120, 120, 120),
+		NeedleLit:  tcell.NewRGBColor(220, 220, 220),
+		TrunkBase:  tcell.NewRGBColor(70, 70, 70),
+		TrunkLit:   tcell.NewRGBColor(150, 150, 150),
+		DecorPalette: []tcell.Color{
+			tcell.NewRGBColor(230, 230, 230),
+			tcell.NewRGBColor(180, 180, 180),
+			tcell.NewRGBColor(255, 255, 255),
+		},
+		SafePalette:  ColorblindSafePalette,
+		StarHueSpeed: 0, // 单色主题里星星不变色，固定白色
+	},
+}
@@ -1,13 +1,24 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"math"
 	"math/rand"
 	"os"
 	"os/signal"
+	"sort"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
+
+	"github.com/AisuKyobu/christmas-cli-tree/internal/bvh"
+	"github.com/AisuKyobu/christmas-cli-tree/internal/camera"
+	"github.com/AisuKyobu/christmas-cli-tree/internal/colorutil"
+	"github.com/AisuKyobu/christmas-cli-tree/internal/particles"
+	"github.com/AisuKyobu/christmas-cli-tree/internal/scene"
+	"github.com/AisuKyobu/christmas-cli-tree/internal/theme"
 	"github.com/gdamore/tcell/v2"
 )
 
@@ -15,32 +26,69 @@ import (
 
 const (
 	TreeTopMargin = 3   // 树顶距离屏幕上方的距离
-	TreeHeight    = 22  // 树的高度
-	TreeBaseWidth = 30  // 树底部的最大宽度（半径）
-	StarSpeed     = 0.2 // 星星公转速度
 	VerticalSpeed = 0.5 // 星星上下移动的频率
 
-	// 可调：星星时间步长（越小移动越慢），你可以在这里自行修改数值
-	StarTimeStep = 0.06
-
-	// 可调：星星照亮范围（默认略小于原来 10.0）
-	LightRadius = 8.0
-
-	// 粒子轨迹参数：延长轨迹特效（初始生命更长，衰减更慢）
-	ParticleInitialLife = 1.2
-	ParticleLifeDecay   = 0.05
 	// 前面（Z>=0）时额外多生成的粒子数，以使正面轨迹更显眼、更长
 	ParticleFrontExtra = 1
+	ParticleLifeDecay  = 0.05 // 粒子生命值每个参考帧（1/25 秒）的衰减量，实际按 dt 折算
 
 	// 天空相关配置
-	SkyStarCount    = 8     // 天空中星星的数量（很少）
-	SkyGlowRadius   = 2     // 星星周围的微弱发光半径（格子单位）
-	SkyBaseR        = 6     // 天空基底颜色 (暗蓝)
-	SkyBaseG        = 10
-	SkyBaseB        = 40
-	SkyTwinkleSpeed = 1.2   // 星星闪烁速度（可调）
+	SkyStarCount    = 8   // 天空中星星的数量（很少）
+	SkyGlowRadius   = 2   // 星星周围的微弱发光半径（格子单位）
+	SkyTwinkleSpeed = 1.2 // 星星闪烁速度（可调）
 )
 
+// cfg 是当前生效的场景配置，默认等价于原先硬编码的常量，
+// 可通过 -scene 指定的 YAML/JSON 文件覆盖（见 loadSceneConfig）。
+var cfg *scene.Config
+
+// sceneTheme 是根据 cfg.SkyColor/DecorColors 生成的主题，作为主题循环列表的
+// 第一项，让 -scene 自定义的天空色/调色板在默认状态下依然生效；用户按
+// `[`/`]` 切到内置主题后才会换成固定配色。
+var sceneTheme theme.Theme
+
+// themeMu 保护下面两个可被按键事件 goroutine 和主循环并发读写的主题状态。
+var themeMu sync.Mutex
+var themeIdx int
+var colorblindSafe bool
+
+// currentTheme 返回当前生效的主题（含 colorblind-safe 调色板选择）。
+func currentTheme() (theme.Theme, bool) {
+	themeMu.Lock()
+	defer themeMu.Unlock()
+	return themeList[themeIdx], colorblindSafe
+}
+
+// themeList 是可循环切换的主题列表：sceneTheme 在前，内置主题紧随其后。
+var themeList []theme.Theme
+
+// cam 是当前的观察视角，方向键调节 Yaw/Pitch，`+`/`-` 调节 Zoom，鼠标拖拽
+// 则同时调节 Yaw/Pitch，实现拖拽环绕效果。camMu 保护它不被按键/鼠标事件的
+// goroutine 和主循环并发读写。
+var cam = camera.New()
+var camMu sync.Mutex
+
+// rotateCamera/zoomCamera 供事件 goroutine 调用，加锁后委托给 cam 自身的方法。
+func rotateCamera(dYaw, dPitch float64) {
+	camMu.Lock()
+	cam.Rotate(dYaw, dPitch)
+	camMu.Unlock()
+}
+
+func zoomCamera(factor float64) {
+	camMu.Lock()
+	cam.ZoomBy(factor)
+	camMu.Unlock()
+}
+
+// snapshotCamera 返回当前相机状态的一份拷贝，供主循环在本帧渲染期间使用，
+// 避免渲染过程中相机状态被事件 goroutine 改到一半。
+func snapshotCamera() camera.Camera {
+	camMu.Lock()
+	defer camMu.Unlock()
+	return *cam
+}
+
 // --- 类型定义 ---
 
 // Vector3 简单的3D坐标
@@ -48,14 +96,6 @@ type Vector3 struct {
 	X, Y, Z float64
 }
 
-// Particle 粒子结构体
-type Particle struct {
-	Pos      Vector3
-	Velocity Vector3
-	Life     float64 // 生命值 0.0 - 1.0
-	Color    tcell.Color
-}
-
 // CellType 单元格类型
 type CellType int
 
@@ -63,7 +103,8 @@ const (
 	TypeEmpty CellType = iota
 	TypeNeedle
 	TypeTrunk
-	TypeDecor // 装饰品
+	TypeDecor // 随机装饰品，颜色跟随当前主题的调色板
+	TypeLight // 预置灯串，固定金色，不随主题变化
 	TypeGift  // 礼物
 )
 
@@ -71,9 +112,24 @@ const (
 type TreeCell struct {
 	Type      CellType
 	Char      rune
-	BaseColor tcell.Color // 原始颜色
+	BaseColor tcell.Color // 原始颜色（TypeNeedle/TypeTrunk/TypeDecor 渲染时改用当前主题的颜色，只有 TypeLight/TypeGift 使用这里的值）
 	LitColor  tcell.Color // 被照亮后的颜色
-	X, Y      int         // 相对于树中心的偏移坐标 (0,0 是树底中心)
+	// Pos 是这个格子在"树坐标系"里的真实 3D 坐标：(0,0,0) 是树底中心，
+	// X/Y 是建树时的平面偏移，Z 是 computeCellDepth 估算出的圆锥表面深度
+	// (Z>=0 朝向观众)。main 循环里通过 camera.Camera.Project(Pos, ...) 把它
+	// 投影到屏幕坐标，而不是简单地把 X/Y 当成屏幕偏移直接相加。
+	Pos Vector3
+	// PaletteIdx 只对 TypeDecor 生效：渲染时用它对当前主题的装饰调色板取模，
+	// 这样切换主题时已经生成好的装饰品能立刻跟着换色，而不用重新建树。
+	PaletteIdx int
+}
+
+// renderCell 是某个 TreeCell 在当前帧、当前相机视角下投影出的屏幕坐标与深度，
+// 只在每帧渲染时临时计算，不随 treeData 持久化。
+type renderCell struct {
+	idx   int
+	x, y  int
+	depth float64
 }
 
 // SkyStar 表示天空中的一个小星星
@@ -83,17 +139,122 @@ type SkyStar struct {
 	Speed float64 // 个别闪烁速度微差
 }
 
+// SnowFlake 是一片还在空中飘落的雪花，落地（或落到树/礼物上）后会从这个切片
+// 移除，转而计入 snowAccum 的堆积深度。
+type SnowFlake struct {
+	X, Y    float64
+	FallVel float64 // 下落速度（格/秒）
+	Phase   float64 // 横向飘动的相位，使每片雪花的风摆动作不同步
+}
+
+// 雪花下落与飘动参数（密度可通过场景配置调整，其余沿用内置常量即可）。
+const (
+	SnowFallSpeedMin  = 2.0  // 最慢下落速度（格/秒）
+	SnowFallSpeedMax  = 4.5  // 最快下落速度（格/秒）
+	SnowWindAmplitude = 1.2  // 横向风力摆动幅度（格/秒）
+	SnowWindFreq      = 0.8  // 横向风力摆动频率
+	SnowMaxFlakes     = 400  // 同时在空中飘落的雪花数量上限
+	SnowMaxDepth      = 3    // 堆积层数上限，超过后不再变厚
+	SnowDecayChance   = 0.01 // 每帧每个堆积格子消融一层的概率
+)
+
 // --- 全局变量 ---
 var (
-	particles []Particle
-	rnd       = rand.New(rand.NewSource(time.Now().UnixNano()))
+	rnd = rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	skyStars  []SkyStar
+	prevWidth = -1
+	prevTopY  = -1
 
-	skyStars   []SkyStar
-	prevWidth  = -1
-	prevTopY   = -1
+	snowFlakes []SnowFlake
+	// snowAccum 记录每个屏幕格子上积雪的堆叠深度，key 是 [x,y]。
+	snowAccum = make(map[[2]int]int)
 )
 
+// bufCell 是帧缓冲里一个格子的内容。
+type bufCell struct {
+	Ch    rune
+	Style tcell.Style
+}
+
+// FrameBuffer 是一个前/后双缓冲：sky/tree/particles/star 各渲染层都只把内容
+// 写进 back，Flush 时逐格 diff against front，只对真正变化的格子调用
+// screen.SetContent，然后交换两个缓冲区。相比每帧 screen.Clear() 再整屏重绘，
+// 这把每帧的开销从 O(width*height) 降到了“实际发生变化的格子数”。
+type FrameBuffer struct {
+	width, height int
+	front, back   []bufCell
+}
+
+// Resize 在终端尺寸变化时重新分配缓冲区；分配后的第一帧里 front 全是空格子，
+// 保证尺寸变化后那一帧会整屏重绘一次。
+func (fb *FrameBuffer) Resize(width, height int) {
+	if width == fb.width && height == fb.height {
+		return
+	}
+	fb.width, fb.height = width, height
+	fb.back = make([]bufCell, width*height)
+	fb.front = make([]bufCell, width*height)
+}
+
+func (fb *FrameBuffer) index(x, y int) (int, bool) {
+	if x < 0 || x >= fb.width || y < 0 || y >= fb.height {
+		return 0, false
+	}
+	return y*fb.width + x, true
+}
+
+// SetContent 实现 particles.Surface，发射器和主循环都可以像操作
+// tcell.Screen 一样直接调用它。
+func (fb *FrameBuffer) SetContent(x, y int, mainc rune, combc []rune, style tcell.Style) {
+	if i, ok := fb.index(x, y); ok {
+		fb.back[i] = bufCell{Ch: mainc, Style: style}
+	}
+}
+
+// Size 实现 particles.Surface。
+func (fb *FrameBuffer) Size() (int, int) {
+	return fb.width, fb.height
+}
+
+// Flush 把 back 和 front 逐格比较，只对发生变化的格子提交给 screen，再交换
+// 缓冲区、清空新的 back，最后调用 screen.Show()。
+func (fb *FrameBuffer) Flush(screen tcell.Screen) {
+	for i, back := range fb.back {
+		if back != fb.front[i] {
+			x, y := i%fb.width, i/fb.width
+			screen.SetContent(x, y, back.Ch, nil, back.Style)
+		}
+	}
+	fb.front, fb.back = fb.back, fb.front
+	for i := range fb.back {
+		fb.back[i] = bufCell{}
+	}
+	screen.Show()
+}
+
 func main() {
+	// 0. 解析命令行参数并加载场景配置
+	scenePath := flag.String("scene", "", "从 YAML/JSON 文件加载自定义场景 (树形/礼物/调色板/动画速度)")
+	dumpDefaultScene := flag.String("dump-default-scene", "", "将内置默认场景写入指定文件 (扩展名 .yaml/.yml/.json) 并退出")
+	effectsFlag := flag.String("effects", "star,firework,snow", "逗号分隔，启用的特效子系统 (star,firework,snow)")
+	flag.Parse()
+	enabledEffects := parseEffectsFlag(*effectsFlag)
+
+	if *dumpDefaultScene != "" {
+		if err := scene.DumpDefault(*dumpDefaultScene); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("默认场景已写入 %s\n", *dumpDefaultScene)
+		return
+	}
+
+	if err := loadSceneConfig(*scenePath); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
 	// 1. 初始化 Tcell
 	screen, err := tcell.NewScreen()
 	if err != nil {
@@ -105,13 +266,52 @@ func main() {
 		os.Exit(1)
 	}
 	defer screen.Fini()
+	screen.EnableMouse() // 支持鼠标拖拽环绕相机
 
 	// 2. 构建树和礼物的数据 (静态数据，只构建一次)
 	treeData := buildRichTreeData()
+	for i := range treeData {
+		treeData[i].Pos.Z = computeCellDepth(treeData[i])
+	}
+
+	// 2a. 在静态树数据上建一次 BVH，供每帧的光照/遮挡查询复用，
+	// 避免对所有格子做线性扫描。
+	cellBVH := buildCellBVH(treeData)
+
+	// 2b. 按 -effects 启用的发射器组装粒子子系统。curWidth/curTopY/curBaseY 和
+	// currentStarPos/currentStarColor 在主循环里每帧刷新，发射器通过闭包读取
+	// 最新值，这样发射器本身不需要知道屏幕尺寸是怎么变化的。
+	var curWidth, curTopY, curBaseY int
+	var currentStarPos particles.Vec3
+	var currentStarColor tcell.Color
+
+	var activeEmitters []particles.Emitter
+	if enabledEffects["star"] {
+		activeEmitters = append(activeEmitters, particles.NewStarTrailEmitter(
+			rnd, cfg.ParticleInitialLife, ParticleLifeDecay, ParticleFrontExtra,
+			func() (particles.Vec3, tcell.Color) { return currentStarPos, currentStarColor },
+		))
+	}
+	if enabledEffects["firework"] {
+		activeEmitters = append(activeEmitters, particles.NewFireworkEmitter(
+			rnd, 9.0, 0.6, 5, 3.0,
+			func() (int, int, int) { return curWidth, curTopY, curBaseY },
+		))
+	}
 
 	// 3. 事件监听
 	quit := make(chan struct{})
 	go func() {
+		// dragX/dragY/dragging 跟踪鼠标左键拖拽的起点，用于把拖拽位移换算成
+		// 相机的 Yaw/Pitch 增量，实现"拖拽环绕"效果。
+		var dragging bool
+		var dragX, dragY int
+		const (
+			keyRotateStep   = 5 * math.Pi / 180 // 方向键每次旋转 5 度
+			mouseOrbitScale = 2 * math.Pi / 180 // 鼠标每移动 1 格对应的弧度
+			zoomInFactor    = 1.1
+			zoomOutFactor   = 1 / 1.1
+		)
 		for {
 			ev := screen.PollEvent()
 			switch ev := ev.(type) {
@@ -120,6 +320,51 @@ func main() {
 					close(quit)
 					return
 				}
+				switch ev.Key() {
+				case tcell.KeyLeft:
+					rotateCamera(-keyRotateStep, 0)
+				case tcell.KeyRight:
+					rotateCamera(keyRotateStep, 0)
+				case tcell.KeyUp:
+					rotateCamera(0, -keyRotateStep)
+				case tcell.KeyDown:
+					rotateCamera(0, keyRotateStep)
+				}
+				switch ev.Rune() {
+				case '[':
+					themeMu.Lock()
+					themeIdx = (themeIdx - 1 + len(themeList)) % len(themeList)
+					themeMu.Unlock()
+				case ']':
+					themeMu.Lock()
+					themeIdx = (themeIdx + 1) % len(themeList)
+					themeMu.Unlock()
+				case 'c':
+					themeMu.Lock()
+					colorblindSafe = !colorblindSafe
+					themeMu.Unlock()
+				case '+', '=':
+					zoomCamera(zoomInFactor)
+				case '-', '_':
+					zoomCamera(zoomOutFactor)
+				}
+			case *tcell.EventMouse:
+				x, y := ev.Position()
+				if ev.Buttons()&tcell.Button1 != 0 {
+					if dragging {
+						rotateCamera(float64(x-dragX)*mouseOrbitScale, float64(y-dragY)*mouseOrbitScale)
+					}
+					dragX, dragY = x, y
+					dragging = true
+				} else {
+					dragging = false
+				}
+				switch ev.Buttons() {
+				case tcell.WheelUp:
+					zoomCamera(zoomInFactor)
+				case tcell.WheelDown:
+					zoomCamera(zoomOutFactor)
+				}
 			case *tcell.EventResize:
 				screen.Sync()
 			}
@@ -138,10 +383,11 @@ func main() {
 		}
 	}()
 
-	ticker := time.NewTicker(time.Millisecond * 40) // 25 FPS
+	ticker := time.NewTicker(time.Second / time.Duration(cfg.FPS))
 	defer ticker.Stop()
 
 	t := 0.0 // 时间变量
+	fb := &FrameBuffer{}
 
 	// 4. 主循环
 	for {
@@ -149,15 +395,20 @@ func main() {
 		case <-quit:
 			return
 		case <-ticker.C:
-			screen.Clear()
 			width, height := screen.Size()
-			
+			fb.Resize(width, height)
+
 			// 计算屏幕中心
 			midX := width / 2
 			// 树底部在屏幕的位置 (留出一点底部空间)
-			baseY := height - 4 
+			baseY := height - 4
 			// 树顶在屏幕的位置
-			topY := baseY - TreeHeight
+			topY := baseY - cfg.TreeHeight
+
+			curWidth, curTopY, curBaseY = width, topY, baseY
+
+			// 当前生效的主题，本帧内所有颜色计算都从这里取，保证同一帧画面一致。
+			th, colorblindSafe := currentTheme()
 
 			// 如果屏幕宽度或树顶位置变化，重建天空星星
 			if width != prevWidth || topY != prevTopY {
@@ -167,13 +418,15 @@ func main() {
 			}
 
 			// --- SKY: 绘制天空背景与微弱发光星星 ---
-			// 绘制天空的基底背景（仅在树上方）
+			// 绘制天空的基底背景（仅在树上方），按行在 SkyTop/SkyBottom 间做
+			// 纵向渐变，取代原来单一平涂的 SkyBaseRGB。
+			skyDenom := float64(max(height-1, 1))
 			for y := 0; y < topY; y++ {
+				bg := th.SkyColorAt(float64(y) / skyDenom)
+				st := tcell.StyleDefault.Background(bg)
 				for x := 0; x < width; x++ {
-					bg := tcell.NewRGBColor(SkyBaseR, SkyBaseG, SkyBaseB)
-					st := tcell.StyleDefault.Background(bg)
 					// 使用空格填充背景
-					screen.SetContent(x, y, ' ', nil, st)
+					fb.SetContent(x, y, ' ', nil, st)
 				}
 			}
 
@@ -185,7 +438,7 @@ func main() {
 				colVal := int32(200 + int32(brightness*55))
 				st := tcell.StyleDefault.Foreground(tcell.NewRGBColor(colVal, colVal, colVal)).Background(tcell.ColorReset)
 				if s.Y >= 0 && s.Y < height && s.X >= 0 && s.X < width {
-					screen.SetContent(s.X, s.Y, '.', nil, st)
+					fb.SetContent(s.X, s.Y, '.', nil, st)
 				}
 				// 光晕：在周围格子稍微提升背景亮度（只在树上方区域生效）
 				for dy := -SkyGlowRadius; dy <= SkyGlowRadius; dy++ {
@@ -195,39 +448,48 @@ func main() {
 						if tx < 0 || tx >= width || ty < 0 || ty >= topY {
 							continue
 						}
-						dist := math.Sqrt(float64(dx*dx+dy*dy))
+						dist := math.Sqrt(float64(dx*dx + dy*dy))
 						if dist > float64(SkyGlowRadius) {
 							continue
 						}
 						// 距离越近亮度越高
 						f := (1.0 - dist/float64(SkyGlowRadius)) * brightness * 0.6
-						bg := skyBgColor(f)
+						base := th.SkyColorAt(float64(ty) / skyDenom)
+						bg := skyBgColor(base, f)
 						st2 := tcell.StyleDefault.Background(bg)
-						screen.SetContent(tx, ty, ' ', nil, st2)
+						fb.SetContent(tx, ty, ' ', nil, st2)
 					}
 				}
 			}
 			// --- SKY END ---
 
 			// 使用可调时间步长控制速度（原来是固定 t += 0.1）
-			t += StarTimeStep
+			t += cfg.StarTimeStep
 
 			// --- A. 计算星星的 3D 螺旋轨迹 ---
-			
+
 			// 1. 垂直运动 (Y轴): 使用 Sin 函数实现平滑的上下往复
 			// 范围从 0 (树顶) 到 1 (接近树干)
 			// (Sin(t) + 1) / 2 将 -1~1 映射到 0~1
 			verticalProgress := (math.Sin(t*VerticalSpeed) + 1) / 2
-			
+
 			// 稍微调整范围，让它不要完全碰到树底，也不要飞出树顶太远
 			// 0.05 ~ 0.95
 			clampedProgress := 0.05 + verticalProgress*0.9
-			
-			starY := float64(topY) + clampedProgress*float64(TreeHeight)
+
+			starY := float64(topY) + clampedProgress*float64(cfg.TreeHeight)
 
 			// 2. 半径计算 (圆锥体): 越往下半径越大
-			// 顶部半径很小(1)，底部半径较大(TreeBaseWidth/2 + 余裕)
-			currentRadius := 2.0 + clampedProgress*float64(TreeBaseWidth/2+2)
+			// 拉伸前顶部半径约 2~3，底部半径较大(cfg.TreeBaseWidth/2 + 余裕)
+			// starHorizontalStretch 把这个半径直接拉伸到和 treeData 里格子坐标
+			// （X/Z 最大到 ±cfg.TreeBaseWidth）同一套 3D 单位：不拉伸的话
+			// currentRadius 大约只有树冠实际半径的一半，星星的轨道会明显
+			// "缩"在树冠里面，投影到屏幕上偏窄，喂给 BVH 的光照/遮挡查询也会
+			// 因为和 treeData.Pos 不在同一尺度而算出完全错误的点亮范围/
+			// 前后遮挡。在这里统一拉伸之后，starRelX/starRelZ 不管是用来做
+			// 屏幕投影、相机旋转，还是 BVH 查询，都和树格子共享同一套坐标系。
+			const starHorizontalStretch = 2.0
+			currentRadius := (2.0 + clampedProgress*float64(cfg.TreeBaseWidth/2+2)) * starHorizontalStretch
 
 			// 3. 水平运动 (X, Z): 快速旋转
 			// 加上 offset 让螺旋线在上升和下降时对称但相位不同，形成好看的交错
@@ -235,35 +497,78 @@ func main() {
 			starRelX := math.Cos(rotateSpeed) * currentRadius
 			starRelZ := math.Sin(rotateSpeed) * currentRadius // Z轴：正数在屏幕前，负数在屏幕后
 
-			// 星星的屏幕坐标
-			starScreenX := float64(midX) + starRelX * 2.0 // X轴拉伸一下适配终端字符比例(通常高是宽的2倍)
-			starScreenY := starY
-
-			// 星星颜色 (彩虹变换)
-			starHue := int(t*20) % 360
-			starColor := hsvToRgb(float64(starHue), 1.0, 1.0)
+			// 星星的屏幕坐标：和树格子共用同一个 Camera.Project，让星星的螺旋
+			// 轨迹也跟着相机一起旋转/缩放，而不是套一个独立的固定投影公式。
+			camSnap := snapshotCamera()
+			starScreenXi, starScreenYi, _ := camSnap.Project(
+				camera.Vec3{X: starRelX, Y: starY - float64(baseY), Z: starRelZ}, midX, baseY)
+			starScreenX := float64(starScreenXi)
+			starScreenY := float64(starScreenYi)
+
+			// 星星颜色 (彩虹变换速度由当前主题的 StarHueSpeed 决定，monochrome 主题为 0 时固定白色)
+			var starColor tcell.Color
+			if th.StarHueSpeed == 0 {
+				starColor = tcell.ColorWhite
+			} else {
+				starHue := int(t*th.StarHueSpeed) % 360
+				starColor = colorutil.HSVToRGB(float64(starHue), 1.0, 1.0)
+			}
 
 			// --- B. 更新粒子系统 ---
-			spawnParticles(starScreenX, starScreenY, starRelZ, starColor)
-			updateParticles()
+			currentStarPos = particles.Vec3{X: starScreenX, Y: starScreenY, Z: starRelZ}
+			currentStarColor = starColor
+			dt := 1.0 / float64(cfg.FPS)
+			for _, e := range activeEmitters {
+				e.Update(dt)
+			}
 
 			// --- C. 渲染树木与礼物 (应用光照) ---
-			
+
 			// 使用顶部常量作为光照半径（便于直接调整）
-			lightRadius := LightRadius
+			lightRadius := cfg.LightRadius
+
+			// 星星在"树坐标系"（以树底中心为原点，和 TreeCell.Pos 同一套单位，
+			// 不受相机旋转影响）下的位置：X/Z 直接复用圆锥螺旋轨迹里算出来的
+			// starRelX/starRelZ，Y 是 starY（绝对屏幕行）相对 baseY 的偏移。
+			starCellPos := bvh.Point{X: starRelX, Y: starY - float64(baseY), Z: starRelZ}
+
+			// 通过 BVH 只取光照半径内的候选格子，而不是线性扫描全部格子；
+			// 再对每个候选格子做一次遮挡检测 —— 如果有另一个格子挡在星星和它
+			// 之间（更靠近观众），该格子维持 BaseColor，否则按距离线性混合出
+			// BaseColor→LitColor。
+			litAmount := make(map[int]float64, 64)
+			for _, idx := range cellBVH.Query(starCellPos, lightRadius) {
+				c := treeData[idx]
+				cellPos := bvh.Point{X: c.Pos.X, Y: c.Pos.Y, Z: c.Pos.Z}
+				dx, dy, dz := cellPos.X-starCellPos.X, cellPos.Y-starCellPos.Y, cellPos.Z-starCellPos.Z
+				d := math.Sqrt(dx*dx + dy*dy + dz*dz)
+				if cellBVH.Occludes(starCellPos, cellPos, idx, 1.0) {
+					continue // 被树干或对面的树冠挡住，维持 BaseColor
+				}
+				litAmount[idx] = 1 - d/lightRadius
+			}
+
+			// 把每个树格子投影到屏幕坐标并按投影深度从远到近排序，这样下面的
+			// 渲染循环能按 back-to-front 的顺序画：相机转到任意角度时，靠近
+			// 观众的格子始终覆盖在更远的格子之上，树才真正像在旋转而不是一张
+			// 贴死的 2D 剪影。
+			renderCells := make([]renderCell, 0, len(treeData))
+			for i, cell := range treeData {
+				sx, sy, depth := camSnap.Project(camera.Vec3{X: cell.Pos.X, Y: cell.Pos.Y, Z: cell.Pos.Z}, midX, baseY)
+				if sx < 0 || sx >= width || sy < 0 || sy >= height {
+					continue
+				}
+				renderCells = append(renderCells, renderCell{idx: i, x: sx, y: sy, depth: depth})
+			}
+			sort.Slice(renderCells, func(a, b int) bool { return renderCells[a].depth < renderCells[b].depth })
 
 			// --- 新增：把树的未使用区域填成与天空一致的背景（横向覆盖整个屏幕） ---
 			// 标记所有树单元格占用的位置
 			occupied := make(map[[2]int]bool)
-			for _, cell := range treeData {
-				cellScreenX := midX + cell.X
-				cellScreenY := baseY + cell.Y
-				if cellScreenX < 0 || cellScreenX >= width || cellScreenY < 0 || cellScreenY >= height {
-					continue
-				}
-				occupied[[2]int{cellScreenX, cellScreenY}] = true
+			for _, rc := range renderCells {
+				occupied[[2]int{rc.x, rc.y}] = true
 			}
-			
+
 			// 标记天空星星及其光晕占用位置，避免被填充覆盖
 			skyOccupied := make(map[[2]int]bool)
 			for _, s := range skyStars {
@@ -292,120 +597,106 @@ func main() {
 				bottom = height - 1
 			}
 			for y := topY; y <= bottom; y++ {
+				st := tcell.StyleDefault.Background(th.SkyColorAt(float64(y) / skyDenom))
 				for x := left; x <= right; x++ {
 					if occupied[[2]int{x, y}] || skyOccupied[[2]int{x, y}] {
 						continue
 					}
-					st := tcell.StyleDefault.Background(tcell.NewRGBColor(SkyBaseR, SkyBaseG, SkyBaseB))
-					screen.SetContent(x, y, ' ', nil, st)
+					fb.SetContent(x, y, ' ', nil, st)
 				}
 			}
 			// --- 新增结束 ---
 
-			for _, cell := range treeData {
-				// 计算该单元格在当前屏幕的绝对位置
-				cellScreenX := midX + cell.X
-				cellScreenY := baseY + cell.Y // cell.Y 是负数，相对于 base
+			// --- SNOW: 生成/更新雪花，落到树或地面上后持续堆积 ---
+			if enabledEffects["snow"] {
+				spawnSnow(width)
+				updateSnow(t, dt, width, height, baseY, occupied)
+			}
 
-				// 简单的裁剪，防止画出屏幕
-				if cellScreenX < 0 || cellScreenX >= width || cellScreenY < 0 || cellScreenY >= height {
-					continue
-				}
+			// 按 renderCells 里排好的 back-to-front 顺序绘制：远处的格子先画，
+			// 近处的格子后画并覆盖在上面，旋转时才不会露出背面的格子穿帮。
+			for _, rc := range renderCells {
+				i := rc.idx
+				cell := treeData[i]
+				cellScreenX, cellScreenY := rc.x, rc.y
 
-				// 计算到星星的 2D 距离 (用于光照强度)
-				dx := float64(cellScreenX) - starScreenX
-				dy := float64(cellScreenY) - starScreenY
-				// 修正 X 轴距离权重，因为终端字符非正方形
-				dist := math.Sqrt((dx*0.5)*(dx*0.5) + dy*dy)
+				// 将树的背景设为与天空一致的渐变色
+				finalStyle := tcell.StyleDefault.Background(th.SkyColorAt(float64(cellScreenY) / skyDenom))
 
-				// 将树的背景设为与天空一致的暗蓝色
-				finalStyle := tcell.StyleDefault.Background(tcell.NewRGBColor(SkyBaseR, SkyBaseG, SkyBaseB))
-				
 				// 基础绘制字符
 				drawChar := cell.Char
-				fgColor := cell.BaseColor
-
-				// 光照逻辑
-				if dist < lightRadius {
-					// 距离越近越亮
-					// 对于普通的树叶/干，可以使用 LitColor
-					// 但装饰（TypeDecor）和礼物（TypeGift）在被照亮时不改变颜色
-					if cell.Type != TypeDecor && cell.Type != TypeGift {
-						fgColor = cell.LitColor
-					}
-					// 注意：装饰不再在被照亮时变成 '★'，也保持原色
+
+				// 按格子类型从当前主题取基础色/照亮色：TypeLight（灯串）和
+				// TypeGift（礼物）固定使用建树时的颜色，不随主题变化。
+				base, litCol := cell.BaseColor, cell.LitColor
+				switch cell.Type {
+				case TypeNeedle:
+					base, litCol = th.NeedleBase, th.NeedleLit
+				case TypeTrunk:
+					base, litCol = th.TrunkBase, th.TrunkLit
+				case TypeDecor:
+					palette := th.Palette(colorblindSafe)
+					base = palette[cell.PaletteIdx%len(palette)]
+					litCol = base // 装饰在被照亮时不改变颜色
+				}
+				fgColor := base
+
+				// 光照逻辑：litAmount 已经在 BVH 查询阶段排除了被遮挡的格子，
+				// 装饰（TypeDecor）、灯串（TypeLight）和礼物（TypeGift）在被照亮时依旧不改变颜色。
+				if amount, lit := litAmount[i]; lit && cell.Type != TypeDecor && cell.Type != TypeLight && cell.Type != TypeGift {
+					fgColor = blendColor(base, litCol, amount)
 				}
 
 				finalStyle = finalStyle.Foreground(fgColor)
-				screen.SetContent(cellScreenX, cellScreenY, drawChar, nil, finalStyle)
+				fb.SetContent(cellScreenX, cellScreenY, drawChar, nil, finalStyle)
 			}
 
 			// --- D. 渲染粒子 (在星星之前还是之后？) ---
-			// 简单的粒子渲染，粒子总是发光的
-			for _, p := range particles {
-				if p.Pos.X >= 0 && p.Pos.X < float64(width) && p.Pos.Y >= 0 && p.Pos.Y < float64(height) {
-					// 使用与天空/树相同的背景，避免覆盖时留下“缺块”
-					st := tcell.StyleDefault.Foreground(p.Color).Background(tcell.NewRGBColor(SkyBaseR, SkyBaseG, SkyBaseB))
-					screen.SetContent(int(p.Pos.X), int(p.Pos.Y), '.', nil, st)
-				}
+			// 使用星星所在行的天空渐变色做背景，避免覆盖时留下“缺块”
+			skyBg := th.SkyColorAt(starScreenY / skyDenom)
+			for _, e := range activeEmitters {
+				e.Render(fb, skyBg)
+			}
+			if enabledEffects["snow"] {
+				renderSnow(fb, skyBg)
 			}
 
 			// --- E. 渲染星星 ---
-			// 如果星星在背面 (Z < 0)，不再绘制（去除“半颗星”的可见效果）
-			shouldDrawStar := true
-			if starRelZ < 0.0 {
-				shouldDrawStar = false
-			}
+			// 星星是否可见由真实的 3D 遮挡决定：把视线当成从树坐标系 +Z 方向很远处
+			// （相机未旋转时即屏幕正前方；这个观察点不随相机旋转）的一个
+			// 虚拟观察点、沿同一根 X/Y 柱子射向星星的线段，复用光照阶段同一个
+			// Occludes 做射线-BVH 遮挡检测 —— 如果这条线段上存在一个 Z 更大
+			// （更靠近观众）的树冠/礼物格子，说明星星被挡在树的背面，不应该画
+			// 出来；这替代了原来简单粗暴的 starRelZ < 0 判断。用固定小半径的
+			// Query 代替不了这一步：树冠正面的 Z 只记录了朝向观众那一侧的深度，
+			// 真正挡住星星的格子在 X/Y 上往往离星星很远，只是恰好挡在视线上。
+			viewerPos := bvh.Point{X: starCellPos.X, Y: starCellPos.Y, Z: 2*float64(cfg.TreeBaseWidth) + 10}
+			shouldDrawStar := !cellBVH.Occludes(viewerPos, starCellPos, -1, 1.0)
 
 			if shouldDrawStar {
 				// 星星也使用相同背景，防止重绘时出现背景闪烁不一致
-				st := tcell.StyleDefault.Foreground(starColor).Background(tcell.NewRGBColor(SkyBaseR, SkyBaseG, SkyBaseB)).Bold(true)
-				screen.SetContent(int(starScreenX), int(starScreenY), '★', nil, st)
+				st := tcell.StyleDefault.Foreground(starColor).Background(skyBg).Bold(true)
+				fb.SetContent(int(starScreenX), int(starScreenY), '★', nil, st)
 			}
 
-			screen.Show()
+			fb.Flush(screen)
 		}
 	}
 }
 
 // --- 辅助逻辑 ---
 
-// spawnParticles 生成拖尾粒子
-func spawnParticles(x, y, z float64, color tcell.Color) {
-	// 基础数量
-	count := rnd.Intn(3) + 2
-	// 如果星星在前面，生成更多粒子以延长正面的轨迹特效
-	if z >= 0 {
-		count += ParticleFrontExtra
-	}
-	for i := 0; i < count; i++ {
-		// 随机散布一点点
-		offsetX := (rnd.Float64() - 0.5) * 2.0
-		offsetY := (rnd.Float64() - 0.5) * 1.0
-		
-		particles = append(particles, Particle{
-			Pos: Vector3{X: x + offsetX, Y: y + offsetY, Z: z},
-			// 粒子稍微向下飘落，前面星星粒子更稳定（更小的速度）以延长视觉停留
-			Velocity: Vector3{X: (rnd.Float64() - 0.5) * 0.2, Y: rnd.Float64() * 0.2},
-			Life:     ParticleInitialLife,
-			Color:    color,
-		})
-	}
-}
-
-// updateParticles 更新粒子状态
-func updateParticles() {
-	var alive []Particle
-	for _, p := range particles {
-		p.Pos.X += p.Velocity.X
-		p.Pos.Y += p.Velocity.Y
-		p.Life -= ParticleLifeDecay // 使用较小的衰减以延长轨迹
-
-		if p.Life > 0 {
-			alive = append(alive, p)
+// parseEffectsFlag 解析 -effects 的逗号分隔列表，返回一个便于 O(1) 查询的集合。
+// 空白项会被忽略，这样 "star,,firework" 或收尾多余的逗号也能正常工作。
+func parseEffectsFlag(raw string) map[string]bool {
+	enabled := make(map[string]bool)
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			enabled[name] = true
 		}
 	}
-	particles = alive
+	return enabled
 }
 
 // buildRichTreeData 构建更丰富的树和礼物数据
@@ -413,47 +704,47 @@ func buildRichTreeData() []TreeCell {
 	var cells []TreeCell
 
 	// 1. 构建树叶 (更胖的三角形)
-	for y := 0; y < TreeHeight; y++ {
+	for y := 0; y < cfg.TreeHeight; y++ {
 		// y=0 是树顶 (相对于树本身坐标系)
 		// 坐标转换：由于屏幕是向下增加，我们这里生成的 cell.Y 应该是负数（相对于树底）
-		// 或者我们定义 0 为树底，-TreeHeight 为树顶
-		
-		currentY := -TreeHeight + y // -22 到 -1
-		
+		// 或者我们定义 0 为树底，-cfg.TreeHeight 为树顶
+
+		currentY := -cfg.TreeHeight + y // 树顶在负方向
+
 		// 宽度线性增加
-		width := int(float64(y) / float64(TreeHeight) * float64(TreeBaseWidth))
-		if width < 1 { width = 1 }
+		width := int(float64(y) / float64(cfg.TreeHeight) * float64(cfg.TreeBaseWidth))
+		if width < 1 {
+			width = 1
+		}
 
 		for x := -width; x <= width; x++ {
 			char := '*'
-			baseColor := tcell.ColorGreen
-			litColor := tcell.NewRGBColor(100, 255, 100) // 亮绿色
 
 			// 随机装饰品 (从 15% 略微减少到 10%)
+			// 装饰品不再在建树时固定颜色，而是记一个 PaletteIdx，渲染时对
+			// 当前主题的调色板取模，这样运行时切换主题能立刻看到效果。
 			if rnd.Float64() < 0.10 {
 				char = getRandomDecorChar()
-				baseColor = getRandomDecorColor() // 自身已经发光
-				// 装饰在被照亮时不改变颜色
-				litColor = baseColor
-				cells = append(cells, TreeCell{Type: TypeDecor, Char: char, BaseColor: baseColor, LitColor: litColor, X: x, Y: currentY})
+				cells = append(cells, TreeCell{Type: TypeDecor, Char: char, Pos: Vector3{X: float64(x), Y: float64(currentY)}, PaletteIdx: rnd.Intn(8)})
 				continue
 			}
-			
-			// 随机预置灯条 (从 5% 略微减少到 3%)
+
+			// 随机预置灯条 (从 5% 略微减少到 3%)：固定金色，不随主题变化
 			if rnd.Float64() < 0.03 {
-				char = '•'
-				baseColor = tcell.NewRGBColor(255, 215, 0) // 金色
-				// 灯在被照亮时不改变颜色
-				litColor = baseColor
-				cells = append(cells, TreeCell{Type: TypeDecor, Char: char, BaseColor: baseColor, LitColor: litColor, X: x, Y: currentY})
+				lightColor := tcell.NewRGBColor(255, 215, 0) // 金色
+				cells = append(cells, TreeCell{Type: TypeLight, Char: '•', BaseColor: lightColor, LitColor: lightColor, Pos: Vector3{X: float64(x), Y: float64(currentY)}})
 				continue
 			}
 
 			// 边缘纹理
-			if x == -width { char = '/' }
-			if x == width { char = '\\' }
+			if x == -width {
+				char = '/'
+			}
+			if x == width {
+				char = '\\'
+			}
 
-			cells = append(cells, TreeCell{Type: TypeNeedle, Char: char, BaseColor: baseColor, LitColor: litColor, X: x, Y: currentY})
+			cells = append(cells, TreeCell{Type: TypeNeedle, Char: char, Pos: Vector3{X: float64(x), Y: float64(currentY)}})
 		}
 	}
 
@@ -461,40 +752,36 @@ func buildRichTreeData() []TreeCell {
 	trunkHeight := 4
 	trunkWidth := 5 // 更粗
 	for y := 0; y < trunkHeight; y++ {
-		for x := -trunkWidth/2; x <= trunkWidth/2; x++ {
+		for x := -trunkWidth / 2; x <= trunkWidth/2; x++ {
+			// 树干颜色不再在这里固定，渲染时从当前主题的 TrunkBase/TrunkLit 取。
 			cells = append(cells, TreeCell{
-				Type:      TypeTrunk,
-				Char:      '#', // 实心的树干
-				BaseColor: tcell.NewRGBColor(101, 67, 33), // 深褐色
-				LitColor:  tcell.NewRGBColor(200, 150, 50), // 亮褐色
-				X:         x,
-				Y:         y, // 0 到 3
+				Type: TypeTrunk,
+				Char: '#',                                   // 实心的树干
+				Pos:  Vector3{X: float64(x), Y: float64(y)}, // Y: 0 到 3
 			})
 		}
 	}
 
-	// 3. 构建树下的礼物
-	// 略微减少礼物数量并且缩小尺寸
-	giftConfigs := []struct{ x, w, h int; color tcell.Color }{
-		{-8, 3, 2, tcell.ColorRed},
-		{6, 4, 2, tcell.ColorBlue},
-	}
-
-	for _, g := range giftConfigs {
-		for gh := 0; gh < g.h; gh++ {
-			for gw := 0; gw < g.w; gw++ {
+	// 3. 构建树下的礼物 (来自 cfg.Gifts，可通过场景文件自定义数量/尺寸/颜色)
+	for _, g := range cfg.Gifts {
+		giftColor := mustParseHexColor(g.Color)
+		for gh := 0; gh < g.H; gh++ {
+			for gw := 0; gw < g.W; gw++ {
 				char := 'H' // 礼物盒纹理
-				if gh == g.h/2 { char = '-' } // 丝带
-				if gw == g.w/2 { char = '|' } // 丝带
+				if gh == g.H/2 {
+					char = '-'
+				} // 丝带
+				if gw == g.W/2 {
+					char = '|'
+				} // 丝带
 
 				cells = append(cells, TreeCell{
 					Type:      TypeGift,
 					Char:      char,
-					BaseColor: g.color,
+					BaseColor: giftColor,
 					// 礼物在被照亮时不改变颜色
-					LitColor:  g.color,
-					X:         g.x + gw,
-					Y:         trunkHeight - gh - 1, // 放在树干底部平面
+					LitColor: giftColor,
+					Pos:      Vector3{X: float64(g.X + gw), Y: float64(trunkHeight - gh - 1)}, // 放在树干底部平面
 				})
 			}
 		}
@@ -503,47 +790,117 @@ func buildRichTreeData() []TreeCell {
 	return cells
 }
 
+// computeCellDepth 估算一个树格子在圆锥表面上的深度 (Z)。树是一个半径随高度
+// 线性变化的圆锥，这里把每个 (X,Y) 格子当作落在圆锥正面（面向观众的一侧）的
+// 一点：给定该高度的半径 r，朝向观众的表面上 Z = sqrt(r^2 - X^2)，X 超出半径
+// 时截断到 0。树干和礼物近似当作扁平的实体：树干半径固定取 2.5（其宽度 5 的一半），
+// 礼物统一取 3.0，两者都以树轴 X=0 为圆心。
+func computeCellDepth(cell TreeCell) float64 {
+	var radius float64
+	switch cell.Type {
+	case TypeTrunk:
+		radius = 2.5
+	case TypeGift:
+		radius = 3.0
+	default: // TypeNeedle / TypeDecor / TypeLight：树冠上的格子
+		heightIdx := cell.Pos.Y + float64(cfg.TreeHeight) // 还原成构建时的 0..TreeHeight-1
+		radius = heightIdx / float64(cfg.TreeHeight) * float64(cfg.TreeBaseWidth)
+		if radius < 1 {
+			radius = 1
+		}
+	}
+	x := cell.Pos.X
+	underRoot := radius*radius - x*x
+	if underRoot < 0 {
+		return 0
+	}
+	return math.Sqrt(underRoot)
+}
+
+// buildCellBVH 把树的静态格子数据装进一棵 BVH，键是格子在 cells 中的下标。
+func buildCellBVH(cells []TreeCell) *bvh.Tree {
+	points := make([]bvh.Point, len(cells))
+	for i, c := range cells {
+		points[i] = bvh.Point{X: c.Pos.X, Y: c.Pos.Y, Z: c.Pos.Z}
+	}
+	return bvh.Build(points)
+}
+
+// blendColor 按 t (0..1) 在 base 和 lit 两个颜色之间做线性插值。
+func blendColor(base, lit tcell.Color, t float64) tcell.Color {
+	if t <= 0 {
+		return base
+	}
+	if t >= 1 {
+		return lit
+	}
+	br, bgc, bb := base.RGB()
+	lr, lg, lb := lit.RGB()
+	r := int32(float64(br) + (float64(lr)-float64(br))*t)
+	g := int32(float64(bgc) + (float64(lg)-float64(bgc))*t)
+	b := int32(float64(bb) + (float64(lb)-float64(bb))*t)
+	return tcell.NewRGBColor(r, g, b)
+}
+
 func getRandomDecorChar() rune {
 	chars := []rune{'o', '@', 'O', '8', '&', '$'}
 	return chars[rnd.Intn(len(chars))]
 }
 
-func getRandomDecorColor() tcell.Color {
-	colors := []tcell.Color{
-		tcell.ColorRed,
-		tcell.ColorYellow,
-		tcell.NewRGBColor(255, 105, 180), // HotPink
-		tcell.NewRGBColor(0, 255, 255),   // Cyan
-	}
-	return colors[rnd.Intn(len(colors))]
-}
-
-// hsvToRgb 辅助函数：生成彩虹色
-func hsvToRgb(h, s, v float64) tcell.Color {
-	c := v * s
-	x := c * (1 - math.Abs(math.Mod(h/60.0, 2)-1))
-	m := v - c
-	var r, g, b float64
-	
-	switch {
-	case 0 <= h && h < 60:
-		r, g, b = c, x, 0
-	case 60 <= h && h < 120:
-		r, g, b = x, c, 0
-	case 120 <= h && h < 180:
-		r, g, b = 0, c, x
-	case 180 <= h && h < 240:
-		r, g, b = 0, x, c
-	case 240 <= h && h < 300:
-		r, g, b = x, 0, c
-	case 300 <= h && h < 360:
-		r, g, b = c, 0, x
-	}
-	
-	R := int32((r + m) * 255)
-	G := int32((g + m) * 255)
-	B := int32((b + m) * 255)
-	return tcell.NewRGBColor(R, G, B)
+// mustParseHexColor 解析场景配置里的 "#RRGGBB" 颜色；由于取值已在
+// loadSceneConfig 时通过 cfg.Validate() 校验过，这里出错即说明加载逻辑本身
+// 有 bug，直接 panic 而不是继续往下跑出一棵颜色错乱的树。
+func mustParseHexColor(hex string) tcell.Color {
+	r, g, b, err := scene.ParseHex(hex)
+	if err != nil {
+		panic(fmt.Sprintf("scene: %v (should have been caught by Validate)", err))
+	}
+	return tcell.NewRGBColor(r, g, b)
+}
+
+// loadSceneConfig 加载场景配置：scenePath 为空时使用内置默认场景，否则从
+// 指定的 YAML/JSON 文件加载并校验。加载成功后顺带把 cfg.SkyColor/DecorColors
+// 拼成 sceneTheme，作为主题循环列表的第一项。
+func loadSceneConfig(scenePath string) error {
+	if scenePath == "" {
+		cfg = scene.Default()
+	} else {
+		c, err := scene.Load(scenePath)
+		if err != nil {
+			return err
+		}
+		cfg = c
+	}
+
+	r, g, b, err := scene.ParseHex(cfg.SkyColor)
+	if err != nil {
+		return fmt.Errorf("scene: %w", err)
+	}
+	decor := make([]tcell.Color, 0, len(cfg.DecorColors))
+	for _, hex := range cfg.DecorColors {
+		decor = append(decor, mustParseHexColor(hex))
+	}
+	sceneTheme = theme.Theme{
+		Name:         "scene",
+		SkyTop:       tcell.NewRGBColor(r, g, b),
+		SkyBottom:    tcell.NewRGBColor(min32(r+40, 255), min32(g+50, 255), min32(b+80, 255)),
+		NeedleBase:   tcell.ColorGreen,
+		NeedleLit:    tcell.NewRGBColor(100, 255, 100),
+		TrunkBase:    tcell.NewRGBColor(101, 67, 33),
+		TrunkLit:     tcell.NewRGBColor(200, 150, 50),
+		DecorPalette: decor,
+		SafePalette:  theme.ColorblindSafePalette,
+		StarHueSpeed: 20,
+	}
+	themeList = append([]theme.Theme{sceneTheme}, theme.Themes...)
+	return nil
+}
+
+func min32(a, b int32) int32 {
+	if a < b {
+		return a
+	}
+	return b
 }
 
 // initSkyStars 初始化天空中的星星位置
@@ -563,15 +920,108 @@ func initSkyStars(width, topY int) {
 	}
 }
 
-// 根据亮度生成天空背景颜色（基底加上亮度）
-func skyBgColor(brightness float64) tcell.Color {
-	br := int32(float64(SkyBaseR) + brightness*40.0)
-	bg := int32(float64(SkyBaseG) + brightness*50.0)
-	bb := int32(float64(SkyBaseB) + brightness*80.0)
+// spawnSnow 在屏幕顶部生成新的雪花，数量由 cfg.SnowDensity 控制。
+func spawnSnow(width int) {
+	if len(snowFlakes) >= SnowMaxFlakes || width <= 0 {
+		return
+	}
+	for i := 0; i < cfg.SnowDensity && len(snowFlakes) < SnowMaxFlakes; i++ {
+		snowFlakes = append(snowFlakes, SnowFlake{
+			X:       rnd.Float64() * float64(width),
+			Y:       0,
+			FallVel: SnowFallSpeedMin + rnd.Float64()*(SnowFallSpeedMax-SnowFallSpeedMin),
+			Phase:   rnd.Float64() * 2 * math.Pi,
+		})
+	}
+}
+
+// updateSnow 让空中的雪花随风飘动、下落，落到地面或任意树/礼物格子上时计入
+// snowAccum 的堆积深度；已经堆积的格子会以很小的概率慢慢消融，形成常青但不
+// 会无限变厚的积雪效果。
+func updateSnow(t, dt float64, width, height, baseY int, occupied map[[2]int]bool) {
+	var falling []SnowFlake
+	for _, f := range snowFlakes {
+		vx := SnowWindAmplitude * math.Sin(t*SnowWindFreq+f.Phase)
+		f.X += vx * dt
+		f.Y += f.FallVel * dt
+
+		x, y := int(f.X), int(f.Y)
+		landed := y >= height-1 || occupied[[2]int{x, y}]
+		if f.X < 0 || f.X >= float64(width) {
+			continue // 被风吹出屏幕，直接消失
+		}
+		if landed {
+			if y >= height {
+				y = height - 1
+			}
+			if snowAccum[[2]int{x, y}] < SnowMaxDepth {
+				snowAccum[[2]int{x, y}]++
+			}
+			continue
+		}
+		falling = append(falling, f)
+	}
+	snowFlakes = falling
+
+	// 积雪缓慢消融，避免树下越堆越厚。
+	for cell, depth := range snowAccum {
+		if depth <= 0 {
+			delete(snowAccum, cell)
+			continue
+		}
+		if rnd.Float64() < SnowDecayChance {
+			snowAccum[cell] = depth - 1
+		}
+	}
+
+	_ = baseY // 预留：未来可以按 baseY 限定积雪只出现在树周围区域
+}
+
+// renderSnow 绘制空中的雪花与地面/树上的积雪；surface 既可以是 tcell.Screen
+// 本身，也可以是 FrameBuffer（两者都实现了 particles.Surface）。
+func renderSnow(surface particles.Surface, bg tcell.Color) {
+	width, height := surface.Size()
+	flakeStyle := tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(bg)
+	for _, f := range snowFlakes {
+		x, y := int(f.X), int(f.Y)
+		if x < 0 || x >= width || y < 0 || y >= height {
+			continue
+		}
+		surface.SetContent(x, y, '*', nil, flakeStyle)
+	}
+
+	for cell, depth := range snowAccum {
+		x, y := cell[0], cell[1]
+		if x < 0 || x >= width || y < 0 || y >= height {
+			continue
+		}
+		ch := '.'
+		switch {
+		case depth >= 3:
+			ch = '*'
+		case depth == 2:
+			ch = ','
+		}
+		surface.SetContent(x, y, ch, nil, tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(bg))
+	}
+}
+
+// 根据亮度在 base（该行的天空渐变色）基础上叠加星星光晕
+func skyBgColor(base tcell.Color, brightness float64) tcell.Color {
+	baseR, baseG, baseB := base.RGB()
+	br := int32(float64(baseR) + brightness*40.0)
+	bg := int32(float64(baseG) + brightness*50.0)
+	bb := int32(float64(baseB) + brightness*80.0)
 	// 限制到 0..255
-	if br > 255 { br = 255 }
-	if bg > 255 { bg = 255 }
-	if bb > 255 { bb = 255 }
+	if br > 255 {
+		br = 255
+	}
+	if bg > 255 {
+		bg = 255
+	}
+	if bb > 255 {
+		bb = 255
+	}
 	return tcell.NewRGBColor(br, bg, bb)
 }
 
@@ -581,4 +1031,4 @@ func max(a, b int) int {
 		return a
 	}
 	return b
-}
\ No newline at end of file
+}